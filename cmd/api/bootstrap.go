@@ -2,27 +2,71 @@ package main
 
 import (
 	"external-metrics/config"
+	adminmetrics "external-metrics/metrics/api/admin"
 	coingeckometrics "external-metrics/metrics/api/coingecko"
+	statusmetrics "external-metrics/metrics/api/status"
+	"external-metrics/pkg/apikeys"
 	coingeckoprovider "external-metrics/pkg/coingecko"
+	"external-metrics/pkg/middleware"
+	"external-metrics/pkg/networks"
+	"external-metrics/pkg/ratelimit"
+	"external-metrics/pkg/tokenlist"
 	"external-metrics/pkg/tools/logging"
+	"external-metrics/pkg/tools/prom"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	ginlogrus "github.com/toorop/gin-logrus"
 )
 
 func bootstrapAPI(
 	coingeckoProvider *coingeckoprovider.Provider,
+	networkRegistry *networks.Registry,
+	tokens *tokenlist.Registry,
+	keys *apikeys.Store,
 	cfg *config.Config,
 	logger *logging.Logger,
 ) *http.Server {
 	router := gin.New()
-	router.Use(ginlogrus.Logger(logger.Logger))
+	if err := router.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		logger.Panicf("Invalid trusted proxies: %v", err)
+	}
+	router.Use(middleware.RequestID(logger))
+	router.Use(middleware.AccessLog(logger))
+	router.Use(middleware.Metrics())
+	router.Use(middleware.Tracing(cfg.Tracing.ServiceName))
+	if len(cfg.App.CORS.AllowedOrigins) > 0 {
+		corsOpts := middleware.CORSOptions(cfg.App.CORS)
+		if err := corsOpts.Validate(); err != nil {
+			logger.Panicf("Invalid CORS config: %v", err)
+		}
+		router.Use(middleware.CORS(corsOpts))
+	}
+	if cfg.App.SecurityHeaders.Enabled {
+		router.Use(middleware.SecurityHeaders(middleware.SecurityHeadersOptions{
+			HSTSMaxAge:            cfg.App.SecurityHeaders.HSTSMaxAge,
+			ContentSecurityPolicy: cfg.App.SecurityHeaders.ContentSecurityPolicy,
+		}))
+	}
+	if cfg.App.Compression.Enabled {
+		router.Use(middleware.Compression(cfg.App.Compression.MinSize, cfg.App.Compression.Level))
+	}
+	router.Use(middleware.CacheControl())
+
+	router.GET("/metrics", gin.WrapH(prom.DefaultRegistry.Handler()))
+	router.GET("/healthz", statusmetrics.Healthz(logger))
+	router.GET("/readyz", statusmetrics.Readyz(coingeckoProvider, cfg, logger))
 
 	apiV1 := router.Group("/api/v1")
+	defaultLimiter, routeLimiters := newRateLimiters(cfg.RateLimit)
+	apiV1.Use(middleware.IPRateLimit(defaultLimiter, routeLimiters, logger))
+	if keys.Enabled() {
+		apiV1.Use(middleware.APIKey(keys, logger))
+		apiV1.GET("/admin/usage", middleware.RequireAdmin(), adminmetrics.GetUsage(keys, logger))
+	}
 
-	attachRoutesAPI(coingeckoProvider, logger, apiV1)
+	attachRoutesAPI(coingeckoProvider, networkRegistry, tokens, logger, apiV1)
+	apiV1.GET("/status", statusmetrics.GetStatus(coingeckoProvider, time.Now(), logger))
 
 	return &http.Server{
 		Handler:      router,
@@ -34,6 +78,8 @@ func bootstrapAPI(
 
 func attachRoutesAPI(
 	coingeckoProvider *coingeckoprovider.Provider,
+	networkRegistry *networks.Registry,
+	tokens *tokenlist.Registry,
 	logger *logging.Logger,
 	router *gin.RouterGroup,
 ) {
@@ -41,14 +87,46 @@ func attachRoutesAPI(
 
 	router.GET("/coin/info", coingeckometrics.GetCoinInfo(coingeckoProvider, logger))
 	router.GET("/coin/chart", coingeckometrics.GetCoinChart(coingeckoProvider, logger))
+	router.GET("/coin/history", coingeckometrics.GetCoinHistory(coingeckoProvider, logger))
+	router.POST("/prices/historical", coingeckometrics.GetHistoricalPrices(coingeckoProvider, logger))
+	router.GET("/convert", coingeckometrics.Convert(coingeckoProvider, logger))
+	router.GET("/currencies", coingeckometrics.GetSupportedCurrencies(coingeckoProvider, logger))
+
+	router.GET("/networks", coingeckometrics.GetNetworks(networkRegistry, logger))
+	router.GET("/token/info", coingeckometrics.GetDefiTokenInfo(coingeckoProvider, networkRegistry, tokens, logger))
+	router.GET("/token/prices", coingeckometrics.GetTokenPrices(coingeckoProvider, networkRegistry, logger))
+	router.GET("/tokenlist", coingeckometrics.GetTokenList(coingeckoProvider, networkRegistry, tokens, logger))
+}
+
+// newRateLimiters returns default and per-route limiters, nil for disabled limits.
+func newRateLimiters(cfg config.RateLimit) (*ratelimit.Limiter, map[string]*ratelimit.Limiter) {
+	var defaultLimiter *ratelimit.Limiter
+	if cfg.PerMinute > 0 {
+		defaultLimiter = ratelimit.New(cfg.PerMinute, cfg.Burst)
+	}
+
+	routeLimiters := make(map[string]*ratelimit.Limiter, len(cfg.Routes))
+	for route, rate := range cfg.Routes {
+		if rate.PerMinute > 0 {
+			routeLimiters[route] = ratelimit.New(rate.PerMinute, rate.Burst)
+		} else {
+			routeLimiters[route] = nil
+		}
+	}
 
-	router.GET("/token/info", coingeckometrics.GetDefiTokenInfo(coingeckoProvider, logger))
+	return defaultLimiter, routeLimiters
 }
 
 func startServer(srv *http.Server) <-chan error {
 	errChan := make(chan error, 1)
 	go func() {
-		err := srv.ListenAndServe()
+		var err error
+		if srv.TLSConfig != nil {
+			// certificates are taken from TLSConfig
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
 		errChan <- err
 	}()
 
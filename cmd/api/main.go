@@ -3,8 +3,14 @@ package main
 import (
 	"context"
 	"external-metrics/config"
+	"external-metrics/metrics/models"
+	"external-metrics/pkg/apikeys"
 	coingeckoprovider "external-metrics/pkg/coingecko"
+	"external-metrics/pkg/networks"
+	"external-metrics/pkg/tlsconfig"
+	"external-metrics/pkg/tokenlist"
 	"external-metrics/pkg/tools/logging"
+	"external-metrics/pkg/tools/tracing"
 	"flag"
 	"io"
 	"log"
@@ -15,6 +21,13 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// coinIndexRetryInterval pause between attempts to load coin index
+	coinIndexRetryInterval = 30 * time.Second
+	// coinIndexRefreshInterval pause between reloads of loaded coin index
+	coinIndexRefreshInterval = 6 * time.Hour
+)
+
 func main() {
 
 	configPath := flag.String("c", "", "config/config.yaml")
@@ -28,7 +41,12 @@ func main() {
 		log.Fatalf("Can't get config from yaml: %v", err)
 	}
 
-	f, err := os.OpenFile(cfg.App.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	f, err := logging.NewRotatingFile(cfg.App.LogFile, logging.RotateOptions{
+		MaxSize:    cfg.App.LogMaxSizeMB << 20,
+		Interval:   cfg.App.LogRotateEvery,
+		MaxBackups: cfg.App.LogMaxBackups,
+		MaxAge:     cfg.App.LogMaxAge,
+	})
 	if err != nil {
 		log.Fatalf("Can't open log file: %v", err)
 	}
@@ -45,17 +63,94 @@ func main() {
 		log.Panic(err)
 	}
 
-	logger := logging.InitLoggerNew(logLevel, io.MultiWriter(os.Stdout, f))
+	logger, err := logging.New(logLevel, cfg.App.LogFormat, io.MultiWriter(os.Stdout, f))
+	if err != nil {
+		log.Panic(err)
+	}
 	logger.Debug("Application is setting up")
 
+	// tracing
+	shutdownTracing, err := tracing.Init(tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Panic("Init tracing error: ", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Print("can't shutdown tracing: ", err)
+		}
+	}()
+
 	// coingecko service
 	coingeckoProvider, err := coingeckoprovider.NewProvider(cfg.Coingecko.APIAddress, logger)
 	if err != nil {
 		log.Panic("Create coingeckoProvider error: ", err)
 	}
+	coingeckoProvider.SetRateLimit(cfg.Coingecko.PerMinute, cfg.Coingecko.Burst)
+
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+
+	// coin index is loaded and refreshed in background, /readyz fails until it is loaded
+	go coingeckoProvider.WatchCoinIndex(watchCtx, coinIndexRetryInterval, coinIndexRefreshInterval)
+
+	// networks registry
+	explorers := make(map[string]models.ExplorerResp)
+	if cfg.Etherscan.APIAddress != "" {
+		explorers[networks.PlatformEthereum] = models.ExplorerResp{Name: "etherscan", APIAddress: cfg.Etherscan.APIAddress}
+	}
+	if cfg.Bscscan.APIAddress != "" {
+		explorers[networks.PlatformBSC] = models.ExplorerResp{Name: "bscscan", APIAddress: cfg.Bscscan.APIAddress}
+	}
+	networkRegistry := networks.NewRegistry(coingeckoProvider, explorers, logger)
+
+	// token lists
+	tokens, err := tokenlist.Load(cfg.Tokenlist.Name, cfg.Tokenlist.Files, cfg.Tokenlist.State)
+	if err != nil {
+		log.Panic("Load token lists error: ", err)
+	}
+
+	// api keys
+	keys, err := apikeys.NewStore(cfg.Auth.Keys, cfg.Auth.KeysFile, logger)
+	if err != nil {
+		log.Panic("Load api keys error: ", err)
+	}
+	keysReloadInterval := cfg.Auth.KeysReloadInterval
+	if keysReloadInterval <= 0 {
+		keysReloadInterval = 10 * time.Second
+	}
+	go keys.Watch(watchCtx, keysReloadInterval)
 
 	// bootstrap server
-	workspaceAPI := bootstrapAPI(coingeckoProvider, cfg, logger)
+	workspaceAPI := bootstrapAPI(coingeckoProvider, networkRegistry, tokens, keys, cfg, logger)
+
+	// tls
+	if cfg.App.TLS.CertFile != "" {
+		tlsLoader, err := tlsconfig.New(tlsconfig.Options{
+			CertFile:     cfg.App.TLS.CertFile,
+			KeyFile:      cfg.App.TLS.KeyFile,
+			MinVersion:   cfg.App.TLS.MinVersion,
+			ClientCAFile: cfg.App.TLS.ClientCAFile,
+			ClientAuth:   cfg.App.TLS.ClientAuth,
+		}, logger)
+		if err != nil {
+			log.Panic("Load tls config error: ", err)
+		}
+		workspaceAPI.TLSConfig = tlsLoader.TLSConfig()
+
+		tlsReloadInterval := cfg.App.TLS.ReloadInterval
+		if tlsReloadInterval <= 0 {
+			tlsReloadInterval = time.Minute
+		}
+		go tlsLoader.Watch(watchCtx, tlsReloadInterval)
+	}
 
 	// graceful shutdown
 	signalChan := make(chan os.Signal, 1)
@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"time"
+
+	"external-metrics/pkg/apikeys"
 
 	"gopkg.in/yaml.v2"
 )
@@ -11,16 +14,66 @@ type Config struct {
 	Coingecko
 	Etherscan
 	Bscscan
+	Tokenlist
+	Tracing
+	Auth
+	RateLimit `yaml:"rate_limit"`
 }
 
 type App struct {
-	APIAddress string `yaml:"address"`
-	LogFile    string `yaml:"log_file"`
-	LogLevel   string `yaml:"log_level"`
+	APIAddress     string        `yaml:"address"`
+	LogFile        string        `yaml:"log_file"`
+	LogLevel       string        `yaml:"log_level"`
+	LogFormat      string        `yaml:"log_format"`       // json or text, json by default
+	LogMaxSizeMB   int64         `yaml:"log_max_size_mb"`  // rotate log file over this size, 0 - no limit
+	LogRotateEvery time.Duration `yaml:"log_rotate_every"` // rotate log file every period, e.g. 24h, 0 - never
+	LogMaxBackups  int           `yaml:"log_max_backups"`  // rotated files to keep, 0 - all
+	LogMaxAge      time.Duration `yaml:"log_max_age"`      // remove rotated files older than this, 0 - never
+
+	TrustedProxies []string `yaml:"trusted_proxies"` // IPs and CIDRs of proxies whose X-Forwarded-For is trusted
+
+	CORS            CORS            `yaml:"cors"`
+	Compression     Compression     `yaml:"compression"`
+	SecurityHeaders SecurityHeaders `yaml:"security_headers"`
+	TLS             TLS             `yaml:"tls"`
+}
+
+// TLS is disabled when CertFile is empty.
+type TLS struct {
+	CertFile       string        `yaml:"cert_file"`
+	KeyFile        string        `yaml:"key_file"`
+	MinVersion     string        `yaml:"min_version"`     // 1.2 by default
+	ClientCAFile   string        `yaml:"client_ca_file"`  // CA of client certificates for mTLS
+	ClientAuth     string        `yaml:"client_auth"`     // require (default) or verify_if_given
+	ReloadInterval time.Duration `yaml:"reload_interval"` // How often files are checked for changes, 1m by default
+}
+
+// CORS is disabled when AllowedOrigins is empty.
+type CORS struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins"` // "*", exact origins or https://*.example.com
+	AllowedMethods   []string      `yaml:"allowed_methods"`
+	AllowedHeaders   []string      `yaml:"allowed_headers"`
+	ExposedHeaders   []string      `yaml:"exposed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"` // Not allowed with "*" origin
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+type Compression struct {
+	Enabled bool `yaml:"enabled"`
+	MinSize int  `yaml:"min_size"` // Smaller responses are sent as is
+	Level   int  `yaml:"level"`    // gzip level 1-9, 0 - default
+}
+
+type SecurityHeaders struct {
+	Enabled               bool          `yaml:"enabled"`
+	HSTSMaxAge            time.Duration `yaml:"hsts_max_age"` // Only when served over TLS, 0 - no header
+	ContentSecurityPolicy string        `yaml:"content_security_policy"`
 }
 
 type Coingecko struct {
 	APIAddress string `yaml:"api"`
+	PerMinute  int    `yaml:"per_minute"` // Outbound requests limit, 0 disables
+	Burst      int    `yaml:"burst"`
 }
 
 type Etherscan struct {
@@ -35,6 +88,38 @@ type Bscscan struct {
 	ContractAddress string `yaml:"contractaddress"`
 }
 
+type Tokenlist struct {
+	Name  string   `yaml:"name"`
+	Files []string `yaml:"files"` // Uniswap tokenlist.json files, later files override earlier
+	State string   `yaml:"state"` // file keeping last served list to version merged list, required with several files
+}
+
+type Tracing struct {
+	Exporter    string  `yaml:"exporter"`     // otlp, stdout or empty to disable
+	Endpoint    string  `yaml:"endpoint"`     // OTLP collector gRPC address
+	Insecure    bool    `yaml:"insecure"`     // plaintext connection to collector
+	ServiceName string  `yaml:"service_name"` // service.name of exported spans
+	SampleRatio float64 `yaml:"sample_ratio"` // share of sampled traces, 0 means all
+}
+
+type Auth struct {
+	KeysFile           string        `yaml:"keys_file"`            // File with keys, reloaded on change
+	KeysReloadInterval time.Duration `yaml:"keys_reload_interval"` // How often keys file is checked, 10s by default
+	Keys               []apikeys.Key `yaml:"keys"`                 // No keys and no file - authentication is disabled
+}
+
+// RateLimit per client IP, PerMinute 0 disables limit.
+type RateLimit struct {
+	PerMinute int                  `yaml:"per_minute"`
+	Burst     int                  `yaml:"burst"`
+	Routes    map[string]RouteRate `yaml:"routes"` // Limits of routes by template, e.g. /api/v1/coin/info
+}
+
+type RouteRate struct {
+	PerMinute int `yaml:"per_minute"`
+	Burst     int `yaml:"burst"`
+}
+
 func New(file string) (*Config, error) {
 	f, err := os.Open(file)
 	if err != nil {
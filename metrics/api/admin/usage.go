@@ -0,0 +1,22 @@
+package adminmetrics
+
+import (
+	"net/http"
+	"time"
+
+	"external-metrics/pkg/apikeys"
+	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUsage статистика запросов по API ключам
+func GetUsage(store *apikeys.Store, logger *logging.Logger) func(c *gin.Context) {
+	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
+		logger.Infof("Start GetUsage...")
+
+		return store.Usage(time.Now()), http.StatusOK, nil
+	})
+}
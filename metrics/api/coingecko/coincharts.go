@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"external-metrics/metrics/models"
 	coingeckoprovider "external-metrics/pkg/coingecko"
 	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/tools/downsample"
 	"external-metrics/pkg/tools/logging"
 
 	"github.com/gin-gonic/gin"
@@ -18,27 +21,48 @@ type GetCoinChartsReq struct {
 	ConvCurr   string `form:"conversion"`
 	RangeStart string `form:"rangeStart"`
 	RangeEnd   string `form:"rangeEnd"`
+	Series     string `form:"series"`
+	Points     int    `form:"points"`
+
+	series []string
 }
 
+// chart series names accepted by the series query arg
+const (
+	chartSeriesPrices       = "prices"
+	chartSeriesMarketCaps   = "market_caps"
+	chartSeriesTotalVolumes = "total_volumes"
+)
+
 // GetCoinChart получение данных для построения графиков цены
 // Data granularity is automatic
 // 1 day from current time = 5 minute interval data
 // 1 - 90 days from current time = hourly data
 // above 90 days from current time = daily data (00:00 UTC)
+// series=prices,market_caps,total_volumes selects returned series (prices by default),
+// points of the selected series are aligned on timestamps
+// points=N downsamples series to N points keeping first/last points and extremes
+// rangeStart/rangeEnd accept unix seconds or milliseconds, RFC3339, YYYY-MM-DD
+// and relative forms (7d, 1y, max), resolved range is returned in response
 func GetCoinChart(coingeckoProvider *coingeckoprovider.Provider, logger *logging.Logger) func(c *gin.Context) {
 	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
 		logger.Infof("Start GetCoinChart...")
 
-		coinChartReq, isErrored := parseGetCoinChartRequest(c, logger)
-		if isErrored {
-			return nil, http.StatusBadRequest, fmt.Errorf("query args parsing error")
+		coinChartReq, err := parseGetCoinChartRequest(c, logger)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+
+		if err := validateConvCurr(c, coingeckoProvider, logger, "conversion", coinChartReq.ConvCurr); err != nil {
+			return nil, httperror.Status(err, http.StatusBadRequest), err
 		}
 
 		logger.Infof("Parse request successfully")
-		logger.Debug("Request is %+v", coinChartReq)
+		logger.Debugf("Request is %+v", coinChartReq)
 
 		coinChartResp, err := coingeckoProvider.GetCoinGeckoCoinChart(
-			c,
+			c.Request.Context(),
 			coinChartReq.CoinShort,
 			coinChartReq.ConvCurr,
 			coinChartReq.RangeStart,
@@ -55,6 +79,13 @@ func GetCoinChart(coingeckoProvider *coingeckoprovider.Provider, logger *logging
 
 		logger.Infof("GetCoinGeckoCoinChart successfully")
 
+		coinChartResp = selectChartSeries(coinChartResp, coinChartReq.series)
+		if coinChartReq.Points > 0 {
+			coinChartResp = downsampleChart(coinChartResp, coinChartReq.series, coinChartReq.Points)
+		}
+		coinChartResp.RangeStart, _ = strconv.ParseInt(coinChartReq.RangeStart, 10, 64)
+		coinChartResp.RangeEnd, _ = strconv.ParseInt(coinChartReq.RangeEnd, 10, 64)
+
 		return coinChartResp, http.StatusOK, nil
 	})
 }
@@ -62,28 +93,201 @@ func GetCoinChart(coingeckoProvider *coingeckoprovider.Provider, logger *logging
 func parseGetCoinChartRequest(
 	c *gin.Context,
 	logger *logging.Logger,
-) (coinChartReq GetCoinChartsReq, isErrored bool) {
-	isErrored = true
-
-	if err := c.ShouldBindQuery(&coinChartReq); err != nil {
+) (coinChartReq GetCoinChartsReq, err error) {
+	if err = c.ShouldBindQuery(&coinChartReq); err != nil {
 		logger.Errorf("Missing parameter(s) in url. %v", err)
-		return
+		return coinChartReq, fmt.Errorf("query args parsing error")
+	}
+
+	coinChartReq.ConvCurr = normalizeConvCurr(coinChartReq.ConvCurr)
+
+	if coinChartReq.Series == "" {
+		coinChartReq.Series = chartSeriesPrices
+	}
+	for _, name := range strings.Split(coinChartReq.Series, ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case chartSeriesPrices, chartSeriesMarketCaps, chartSeriesTotalVolumes:
+			if !containsString(coinChartReq.series, name) {
+				coinChartReq.series = append(coinChartReq.series, name)
+			}
+		default:
+			return coinChartReq, httperror.FieldError("series", fmt.Sprintf("unknown chart series %q", name))
+		}
 	}
 
-	if coinChartReq.ConvCurr == "" {
-		coinChartReq.ConvCurr = "usd"
+	if coinChartReq.Points != 0 && coinChartReq.Points < downsample.MinThreshold {
+		return coinChartReq, httperror.FieldError(
+			"points", fmt.Sprintf("points must be at least %d", downsample.MinThreshold),
+		)
 	}
 
 	// default range is 1 day
-	currentTime := time.Now()
-	rangeStart, err := strconv.ParseInt(coinChartReq.RangeStart, 10, 64)
-	if err != nil || rangeStart > currentTime.Unix() || rangeStart < 0 {
-		coinChartReq.RangeStart = strconv.FormatInt(currentTime.AddDate(0, 0, -1).Unix(), 10)
+	now := time.Now()
+	rangeStart, rangeEnd := now.AddDate(0, 0, -1), now
+	if coinChartReq.RangeStart != "" {
+		if rangeStart, err = parseChartTime(coinChartReq.RangeStart, now); err != nil {
+			return coinChartReq, httperror.FieldError("rangeStart", err.Error())
+		}
+	}
+	if coinChartReq.RangeEnd != "" {
+		if rangeEnd, err = parseChartTime(coinChartReq.RangeEnd, now); err != nil {
+			return coinChartReq, httperror.FieldError("rangeEnd", err.Error())
+		}
+	}
+
+	if rangeStart.After(now) {
+		return coinChartReq, httperror.FieldError("rangeStart", "range start is in the future")
+	}
+	if rangeEnd.After(now.Add(chartClockSkew)) {
+		return coinChartReq, httperror.FieldError("rangeEnd", "range end is in the future")
+	}
+	if !rangeStart.Before(rangeEnd) {
+		return coinChartReq, httperror.FieldError("rangeStart", "range start must be before range end")
+	}
+
+	coinChartReq.RangeStart = strconv.FormatInt(rangeStart.Unix(), 10)
+	coinChartReq.RangeEnd = strconv.FormatInt(rangeEnd.Unix(), 10)
+
+	return coinChartReq, nil
+}
+
+// chartClockSkew is tolerated difference between client and server clocks for range end.
+const chartClockSkew = time.Minute
+
+// unixMillisThreshold separates unix seconds from unix milliseconds:
+// 1e11 seconds is far beyond year 5000, while 1e11 milliseconds is 1973.
+const unixMillisThreshold = 1e11
+
+// parseChartTime parses chart range bound. Accepted formats are
+// unix seconds, unix milliseconds, RFC3339 datetime, YYYY-MM-DD date (UTC),
+// relative "Nh", "Nd", "Nw", "Nm", "Ny" meaning N hours/days/weeks/months/years before now
+// and "max" meaning the beginning of the chart.
+func parseChartTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if value == "max" {
+		return time.Unix(0, 0), nil
+	}
+
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if ts < 0 {
+			return time.Time{}, fmt.Errorf("negative timestamp %d", ts)
+		}
+		if ts >= unixMillisThreshold {
+			return time.UnixMilli(ts), nil
+		}
+		return time.Unix(ts, 0), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+
+	if len(value) > 1 {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n > 0 {
+			switch value[len(value)-1] {
+			case 'h':
+				return now.Add(-time.Duration(n) * time.Hour), nil
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			case 'm':
+				return now.AddDate(0, -n, 0), nil
+			case 'y':
+				return now.AddDate(-n, 0, 0), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf(
+		"can't parse %q: expected unix seconds or milliseconds, RFC3339, YYYY-MM-DD, Nh/Nd/Nw/Nm/Ny or max", value,
+	)
+}
+
+// selectChartSeries keeps only requested series and drops points whose timestamp
+// is missing in any of them, so that i-th points of all returned series match.
+func selectChartSeries(chart *models.CoinGeckoCoinChartResp, series []string) *models.CoinGeckoCoinChartResp {
+	selected := make(map[string][][]float64, len(series))
+	for _, name := range series {
+		selected[name] = *chartSeries(chart, name)
+	}
+
+	// count timestamps occurrences among selected series
+	tsCount := make(map[float64]int)
+	for _, points := range selected {
+		seen := make(map[float64]bool, len(points))
+		for _, point := range points {
+			if len(point) < 2 || seen[point[0]] {
+				continue
+			}
+			seen[point[0]] = true
+			tsCount[point[0]]++
+		}
+	}
+
+	// prices are always present in response, as before series selection
+	res := &models.CoinGeckoCoinChartResp{Prices: make([][]float64, 0)}
+	for name, points := range selected {
+		aligned := make([][]float64, 0, len(points))
+		seen := make(map[float64]bool, len(points))
+		for _, point := range points {
+			if len(point) < 2 || seen[point[0]] || tsCount[point[0]] != len(selected) {
+				continue
+			}
+			seen[point[0]] = true
+			aligned = append(aligned, point)
+		}
+		*chartSeries(res, name) = aligned
+	}
+
+	return res
+}
+
+// chartSeries returns pointer to chart series by its name.
+func chartSeries(chart *models.CoinGeckoCoinChartResp, name string) *[][]float64 {
+	switch name {
+	case chartSeriesMarketCaps:
+		return &chart.MarketCaps
+	case chartSeriesTotalVolumes:
+		return &chart.TotalVolumes
+	default:
+		return &chart.Prices
+	}
+}
+
+// downsampleChart reduces aligned series to points points.
+// Points are chosen on the first requested series and the same timestamps are kept in others.
+func downsampleChart(chart *models.CoinGeckoCoinChartResp, series []string, points int) *models.CoinGeckoCoinChartResp {
+	primary := *chartSeries(chart, series[0])
+	indices := downsample.LTTB(primary, points)
+
+	for _, name := range series {
+		s := chartSeries(chart, name)
+		sampled := make([][]float64, 0, len(indices))
+		for _, i := range indices {
+			sampled = append(sampled, (*s)[i])
+		}
+		*s = sampled
 	}
-	rangeEnd, err := strconv.ParseInt(coinChartReq.RangeEnd, 10, 64)
-	if err != nil || rangeEnd > currentTime.Unix() || rangeEnd < 0 {
-		coinChartReq.RangeEnd = strconv.FormatInt(currentTime.Unix(), 10)
+
+	if len(indices) > 1 {
+		first, last := primary[indices[0]][0], primary[indices[len(indices)-1]][0]
+		chart.BucketWidth = int64(last-first) / int64(len(indices)-1)
 	}
 
-	return coinChartReq, false
+	return chart
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,111 @@
+package coingeckometrics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/tools/htmltext"
+)
+
+// description formats accepted by the format query arg
+const (
+	descriptionFormatHTML     = "html"
+	descriptionFormatText     = "text"
+	descriptionFormatMarkdown = "markdown"
+)
+
+// defaultDescriptionLang is used when neither lang nor Accept-Language match a description.
+const defaultDescriptionLang = "en"
+
+// parseDescriptionFormat validates format query arg, html is the default.
+func parseDescriptionFormat(format string) (string, error) {
+	switch format {
+	case "":
+		return descriptionFormatHTML, nil
+	case descriptionFormatHTML, descriptionFormatText, descriptionFormatMarkdown:
+		return format, nil
+	default:
+		return "", httperror.FieldError(
+			"format",
+			fmt.Sprintf(
+				"unknown format %q, supported: %s, %s, %s",
+				format, descriptionFormatHTML, descriptionFormatText, descriptionFormatMarkdown,
+			),
+		)
+	}
+}
+
+// localizeDescription picks description in the requested language (lang arg first,
+// then Accept-Language preferences, then English) and converts it to format.
+// Coingecko descriptions are untrusted html, so html format is sanitized.
+// Result contains the single picked language, or is empty if there is no description.
+func localizeDescription(about map[string]string, lang string, acceptLanguage string, format string) map[string]string {
+	candidates := make([]string, 0)
+	if lang != "" {
+		candidates = append(candidates, lang)
+	}
+	candidates = append(candidates, parseAcceptLanguage(acceptLanguage)...)
+	candidates = append(candidates, defaultDescriptionLang)
+
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(candidate)
+		for _, key := range []string{candidate, strings.SplitN(candidate, "-", 2)[0]} {
+			description, found := about[key]
+			if !found || strings.TrimSpace(description) == "" {
+				continue
+			}
+
+			switch format {
+			case descriptionFormatText:
+				description = htmltext.ToText(description)
+			case descriptionFormatMarkdown:
+				description = htmltext.ToMarkdown(description)
+			default:
+				description = htmltext.Sanitize(description)
+			}
+			return map[string]string{key: description}
+		}
+	}
+
+	return map[string]string{}
+}
+
+// parseAcceptLanguage returns languages of Accept-Language header ordered by preference.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+
+	langs := make([]weighted, 0)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := strings.TrimSpace(fields[0])
+		if lang == "" || lang == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if value, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = value
+				}
+			}
+		}
+		if q > 0 {
+			langs = append(langs, weighted{lang: lang, q: q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	res := make([]string, 0, len(langs))
+	for _, l := range langs {
+		res = append(res, l.lang)
+	}
+	return res
+}
@@ -0,0 +1,90 @@
+package coingeckometrics
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"external-metrics/metrics/models"
+	coingeckoprovider "external-metrics/pkg/coingecko"
+	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetCoinHistoryReq struct {
+	CoinShort string `form:"coin" binding:"required"`
+	Date      string `form:"date" binding:"required"`
+	ConvCurr  string `form:"conversion"`
+
+	date time.Time
+}
+
+// GetCoinHistory получение цены, капитализации и объема монеты на дату (00:00 UTC)
+func GetCoinHistory(coingeckoProvider *coingeckoprovider.Provider, logger *logging.Logger) func(c *gin.Context) {
+	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
+		logger.Infof("Start GetCoinHistory...")
+
+		coinHistoryReq, err := parseGetCoinHistoryRequest(c, logger)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+
+		if err := validateConvCurr(c, coingeckoProvider, logger, "conversion", coinHistoryReq.ConvCurr); err != nil {
+			return nil, httperror.Status(err, http.StatusBadRequest), err
+		}
+
+		logger.Infof("Parse request successfully")
+		logger.Debugf("Request is %+v", coinHistoryReq)
+
+		history, err := coingeckoProvider.GetCoinHistory(c.Request.Context(), coinHistoryReq.CoinShort, coinHistoryReq.date)
+		if err != nil {
+			logger.Errorf("Can't get coin history for %s on %s", coinHistoryReq.CoinShort, coinHistoryReq.Date)
+			return nil, http.StatusNotFound, fmt.Errorf(
+				"failed to get coin history for %s on %s", coinHistoryReq.CoinShort, coinHistoryReq.Date,
+			)
+		}
+
+		price, found := history.MarketData.CurrentPrice[coinHistoryReq.ConvCurr]
+		if !found {
+			logger.Errorf("Can't get price for %s on %s in %s", coinHistoryReq.CoinShort, coinHistoryReq.Date, coinHistoryReq.ConvCurr)
+			return nil, http.StatusNotFound, fmt.Errorf(
+				"no price for %s on %s in %s", coinHistoryReq.CoinShort, coinHistoryReq.Date, coinHistoryReq.ConvCurr,
+			)
+		}
+
+		logger.Infof("GetCoinHistory successfully")
+
+		return &models.CoinHistoryResp{
+			Name:      history.Name,
+			Date:      coinHistoryReq.Date,
+			Price:     price,
+			MarketCap: history.MarketData.MarketCap[coinHistoryReq.ConvCurr],
+			Volume24:  history.MarketData.TotalVolume[coinHistoryReq.ConvCurr],
+		}, http.StatusOK, nil
+	})
+}
+
+func parseGetCoinHistoryRequest(
+	c *gin.Context,
+	logger *logging.Logger,
+) (coinHistoryReq GetCoinHistoryReq, err error) {
+	if err = c.ShouldBindQuery(&coinHistoryReq); err != nil {
+		logger.Errorf("Missing parameter(s) in url. %v", err)
+		return coinHistoryReq, fmt.Errorf("query args parsing error")
+	}
+
+	coinHistoryReq.ConvCurr = normalizeConvCurr(coinHistoryReq.ConvCurr)
+
+	coinHistoryReq.date, err = time.Parse("2006-01-02", coinHistoryReq.Date)
+	if err != nil {
+		return coinHistoryReq, httperror.FieldError("date", "date must be in YYYY-MM-DD format")
+	}
+	if coinHistoryReq.date.After(time.Now().UTC()) {
+		return coinHistoryReq, httperror.FieldError("date", "date is in the future")
+	}
+
+	return coinHistoryReq, nil
+}
@@ -3,6 +3,7 @@ package coingeckometrics
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	coingeckoprovider "external-metrics/pkg/coingecko"
 	"external-metrics/pkg/httperror"
@@ -14,11 +15,20 @@ import (
 type GetCoinInfoReq struct {
 	CoinShort string `form:"coin" binding:"required"`
 	ConvCurr  string `form:"conversion"`
+	Lang      string `form:"lang"`
+	Format    string `form:"format"`
+
+	convCurrs []string
 }
 
 // GetCoinInfo получение информации о токене по его имени
+// conversion может содержать несколько валют через запятую: основные поля заполняются
+// для первой из них, данные по всем валютам возвращаются в currencies
+// описание (about) возвращается на языке lang или из Accept-Language (по умолчанию английский)
+// в формате format=html|text|markdown, html очищается от небезопасной разметки
 func GetCoinInfo(coingeckoProvider *coingeckoprovider.Provider, logger *logging.Logger) func(c *gin.Context) {
 	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
 		logger.Infof("Start GetCoinInfo...")
 
 		coinInfoReq, isErrored := parseGetCoinInfoRequest(c, logger)
@@ -26,10 +36,19 @@ func GetCoinInfo(coingeckoProvider *coingeckoprovider.Provider, logger *logging.
 			return nil, http.StatusBadRequest, fmt.Errorf("query args parsing error")
 		}
 
+		format, err := parseDescriptionFormat(coinInfoReq.Format)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+
+		if err := validateConvCurrs(c, coingeckoProvider, logger, "conversion", coinInfoReq.convCurrs); err != nil {
+			return nil, httperror.Status(err, http.StatusBadRequest), err
+		}
+
 		logger.Infof("Parse request successfully")
-		logger.Debug("Request is %+v", coinInfoReq)
+		logger.Debugf("Request is %+v", coinInfoReq)
 
-		coinInfo, err := coingeckoProvider.GetCoinInfo(c, coinInfoReq.CoinShort, coinInfoReq.ConvCurr)
+		coinInfo, err := coingeckoProvider.GetCoinInfo(c.Request.Context(), coinInfoReq.CoinShort, coinInfoReq.ConvCurr)
 		if err != nil {
 			logger.Errorf(
 				fmt.Sprintf("Can't get coingecko coinInfo for %s %s", coinInfoReq.CoinShort, coinInfoReq.ConvCurr),
@@ -39,6 +58,22 @@ func GetCoinInfo(coingeckoProvider *coingeckoprovider.Provider, logger *logging.
 			)
 		}
 
+		if len(coinInfoReq.convCurrs) > 1 {
+			coinInfo.Currencies, err = coingeckoProvider.GetCoinCurrenciesMarketData(
+				c.Request.Context(), coinInfoReq.CoinShort, coinInfoReq.convCurrs,
+			)
+			if err != nil {
+				logger.Errorf("Can't get coingecko market data for %s in %v", coinInfoReq.CoinShort, coinInfoReq.convCurrs)
+				return nil, http.StatusNotFound, fmt.Errorf(
+					"failed to get coin info for %s with %s conversion", coinInfoReq.CoinShort, strings.Join(coinInfoReq.convCurrs, ","),
+				)
+			}
+		}
+
+		coinInfo.About = localizeDescription(coinInfo.About, coinInfoReq.Lang, c.GetHeader("Accept-Language"), format)
+		// shared caches must not serve description of one language to another
+		c.Writer.Header().Add("Vary", "Accept-Language")
+
 		logger.Infof("GetCoinInfo successfully")
 
 		return coinInfo, http.StatusOK, nil
@@ -55,8 +90,7 @@ func parseGetCoinInfoRequest(
 		logger.Errorf("Missing parameter(s) in url. %v", err)
 		return
 	}
-	if coinInfoReq.ConvCurr == "" {
-		coinInfoReq.ConvCurr = "usd"
-	}
+	coinInfoReq.convCurrs = splitConvCurrs(coinInfoReq.ConvCurr)
+	coinInfoReq.ConvCurr = coinInfoReq.convCurrs[0]
 	return coinInfoReq, false
 }
@@ -0,0 +1,108 @@
+package coingeckometrics
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"external-metrics/metrics/models"
+	coingeckoprovider "external-metrics/pkg/coingecko"
+	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/tools/decimal"
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConvertReq struct {
+	From   string `form:"from" binding:"required"`
+	To     string `form:"to" binding:"required"`
+	Amount string `form:"amount"`
+
+	amount decimal.Decimal
+}
+
+// Convert пересчет суммы между монетами и валютами (coin->coin, coin->fiat, fiat->coin)
+// через опорную валюту
+func Convert(coingeckoProvider *coingeckoprovider.Provider, logger *logging.Logger) func(c *gin.Context) {
+	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
+		logger.Infof("Start Convert...")
+
+		convertReq, err := parseConvertRequest(c, logger)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+
+		logger.Infof("Parse request successfully")
+		logger.Debugf("Request is %+v", convertReq)
+
+		rate, path, err := coingeckoProvider.GetCrossRate(c.Request.Context(), convertReq.From, convertReq.To)
+		if err != nil {
+			logger.Errorf("Can't get cross rate for %s %s: %v", convertReq.From, convertReq.To, err)
+			return nil, http.StatusNotFound, fmt.Errorf(
+				"failed to get conversion rate from %s to %s", convertReq.From, convertReq.To,
+			)
+		}
+
+		result := convertReq.amount.Mul(rate)
+		if math.IsInf(result.Float64(), 0) {
+			return nil, http.StatusBadRequest, httperror.FieldError("amount", "amount is too large to convert")
+		}
+
+		logger.Infof("Convert successfully")
+
+		return &models.ConvertResp{
+			From:   convertReq.From,
+			To:     convertReq.To,
+			Amount: convertReq.amount,
+			Rate:   rate,
+			Result: result,
+			Path:   path,
+		}, http.StatusOK, nil
+	})
+}
+
+func parseConvertRequest(
+	c *gin.Context,
+	logger *logging.Logger,
+) (convertReq ConvertReq, err error) {
+	if err = c.ShouldBindQuery(&convertReq); err != nil {
+		logger.Errorf("Missing parameter(s) in url. %v", err)
+		return convertReq, fmt.Errorf("query args parsing error")
+	}
+
+	convertReq.From = strings.TrimSpace(convertReq.From)
+	convertReq.To = strings.TrimSpace(convertReq.To)
+
+	convertReq.amount, _ = decimal.New("1")
+	if convertReq.Amount != "" {
+		convertReq.amount, err = parseAmount(convertReq.Amount)
+		if err != nil {
+			return convertReq, httperror.FieldError("amount", "amount must be a non-negative finite number")
+		}
+	}
+
+	return convertReq, nil
+}
+
+// parseAmount parses decimal amount within float64 range, NaN, Inf and
+// exponents out of range are rejected before exact arithmetic.
+func parseAmount(s string) (decimal.Decimal, error) {
+	amount, err := decimal.New(s)
+	if err != nil {
+		return amount, err
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || math.IsInf(f, 0) {
+		return amount, fmt.Errorf("amount %q out of range", s)
+	}
+	// underflow to zero of non-zero literal
+	mantissa := strings.SplitN(strings.ToLower(s), "e", 2)[0]
+	if f == 0 && strings.Trim(mantissa, "0.") != "" {
+		return amount, fmt.Errorf("amount %q out of range", s)
+	}
+	return amount, nil
+}
@@ -0,0 +1,103 @@
+package coingeckometrics
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	coingeckoprovider "external-metrics/pkg/coingecko"
+	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSupportedCurrencies список валют, допустимых в параметре conversion
+func GetSupportedCurrencies(coingeckoProvider *coingeckoprovider.Provider, logger *logging.Logger) func(c *gin.Context) {
+	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
+		logger.Infof("Start GetSupportedCurrencies...")
+
+		currencies, err := coingeckoProvider.GetSupportedVsCurrencies(c.Request.Context())
+		if err != nil {
+			return nil, http.StatusInternalServerError, fmt.Errorf("failed to get supported currencies")
+		}
+
+		logger.Infof("GetSupportedCurrencies successfully")
+
+		return currencies, http.StatusOK, nil
+	})
+}
+
+// normalizeConvCurr returns conversion currency in lower case as coingecko expects it,
+// usd if convCurr is empty.
+func normalizeConvCurr(convCurr string) string {
+	convCurr = strings.ToLower(strings.TrimSpace(convCurr))
+	if convCurr == "" {
+		return "usd"
+	}
+	return convCurr
+}
+
+// splitConvCurrs splits comma-separated conversion arg into lower case currencies list.
+func splitConvCurrs(convCurr string) []string {
+	convCurrs := make([]string, 0)
+	for _, currency := range strings.Split(convCurr, ",") {
+		currency = strings.ToLower(strings.TrimSpace(currency))
+		if currency != "" && !containsString(convCurrs, currency) {
+			convCurrs = append(convCurrs, currency)
+		}
+	}
+	if len(convCurrs) == 0 {
+		convCurrs = append(convCurrs, "usd")
+	}
+	return convCurrs
+}
+
+// validateConvCurrs checks every currency of convCurrs with validateConvCurr.
+func validateConvCurrs(
+	c *gin.Context,
+	coingeckoProvider *coingeckoprovider.Provider,
+	logger *logging.Logger,
+	field string,
+	convCurrs []string,
+) error {
+	for _, convCurr := range convCurrs {
+		if err := validateConvCurr(c, coingeckoProvider, logger, field, convCurr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateConvCurr checks that convCurr is supported by coingecko as vs_currency.
+// If the list of supported currencies has never been loaded the currency is rejected
+// with 503 error.
+func validateConvCurr(
+	c *gin.Context,
+	coingeckoProvider *coingeckoprovider.Provider,
+	logger *logging.Logger,
+	field string,
+	convCurr string,
+) error {
+	currencies, err := coingeckoProvider.GetSupportedVsCurrencies(c.Request.Context())
+	if err != nil {
+		logger.Errorf("Can't validate %s %q: %v", field, convCurr, err)
+		return httperror.ErrorView{
+			Code:    http.StatusServiceUnavailable,
+			Field:   field,
+			Message: "supported currencies are unavailable, try again later",
+		}
+	}
+
+	for _, currency := range currencies {
+		if currency == convCurr {
+			return nil
+		}
+	}
+
+	return httperror.FieldError(
+		field,
+		fmt.Sprintf("unsupported currency %q, supported: %s", convCurr, strings.Join(currencies, ", ")),
+	)
+}
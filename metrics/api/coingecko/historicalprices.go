@@ -0,0 +1,234 @@
+package coingeckometrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"external-metrics/metrics/models"
+	coingeckoprovider "external-metrics/pkg/coingecko"
+	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/tools/decimal"
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HistoricalPriceReq struct {
+	CoinShort string `json:"coin"`
+	ConvCurr  string `json:"conversion"`
+	Timestamp int64  `json:"timestamp"` // unix seconds or milliseconds
+}
+
+// maxHistoricalPrices limits number of pairs in one batch request.
+const maxHistoricalPrices = 1000
+
+const (
+	// historicalPricesConcurrency limits charts requested at once.
+	historicalPricesConcurrency = 8
+	// historicalPricesTimeout bounds all chart requests, so that response fits server write timeout.
+	historicalPricesTimeout = 20 * time.Second
+)
+
+// historicalPricesGroup is a set of requested pairs answered by one range chart.
+type historicalPricesGroup struct {
+	coinShort string
+	convCurr  string
+	items     []int // indexes of request pairs
+	from, to  int64
+}
+
+// GetHistoricalPrices получение цен для набора пар (монета, время).
+// Пары группируются по монете, валюте и дню (UTC), для каждой группы запрашивается один график,
+// покрывающий все запрошенные моменты дня, и берется ближайшая к моменту точка.
+// График не длиннее суток, поэтому точки идут не реже раза в час.
+func GetHistoricalPrices(coingeckoProvider *coingeckoprovider.Provider, logger *logging.Logger) func(c *gin.Context) {
+	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
+		logger.Infof("Start GetHistoricalPrices...")
+
+		pricesReq, err := parseGetHistoricalPricesRequest(c, logger)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+
+		checked := make(map[string]bool)
+		for i, req := range pricesReq {
+			if checked[req.ConvCurr] {
+				continue
+			}
+			field := fmt.Sprintf("[%d].conversion", i)
+			if err := validateConvCurr(c, coingeckoProvider, logger, field, req.ConvCurr); err != nil {
+				return nil, httperror.Status(err, http.StatusBadRequest), err
+			}
+			checked[req.ConvCurr] = true
+		}
+
+		logger.Infof("Parse request successfully, %d pairs", len(pricesReq))
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), historicalPricesTimeout)
+		defer cancel()
+
+		// every group fills its own items of resp
+		resp := make([]models.HistoricalPriceResp, len(pricesReq))
+		sem := make(chan struct{}, historicalPricesConcurrency)
+		var wg sync.WaitGroup
+		for _, group := range groupHistoricalPrices(pricesReq) {
+			wg.Add(1)
+			go func(group *historicalPricesGroup) {
+				defer wg.Done()
+
+				var (
+					chart *models.CoinGeckoCoinChartResp
+					err   error
+				)
+				select {
+				case sem <- struct{}{}:
+					from, to := historicalPricesRange(group.from, group.to)
+					chart, err = coingeckoProvider.GetCoinGeckoCoinChart(
+						ctx,
+						group.coinShort,
+						group.convCurr,
+						strconv.FormatInt(from, 10),
+						strconv.FormatInt(to, 10),
+					)
+					<-sem
+				case <-ctx.Done():
+					err = ctx.Err()
+				}
+				if err == nil && len(chart.Prices) == 0 {
+					err = fmt.Errorf("empty chart")
+				}
+				if err != nil {
+					logger.Errorf("Can't get coin chart for %s %s: %v", group.coinShort, group.convCurr, err)
+				}
+
+				for _, i := range group.items {
+					resp[i] = models.HistoricalPriceResp{
+						Coin:       pricesReq[i].CoinShort,
+						Conversion: pricesReq[i].ConvCurr,
+						Timestamp:  pricesReq[i].Timestamp,
+					}
+					if err != nil {
+						resp[i].Error = fmt.Sprintf("failed to get market chart for %s in %s", group.coinShort, group.convCurr)
+						continue
+					}
+
+					point := nearestChartPoint(chart.Prices, pricesReq[i].Timestamp*1000)
+					price := decimal.NewFromFloat(point[1])
+					resp[i].Price = &price
+					resp[i].PointTimestamp = int64(point[0]) / 1000
+					resp[i].Delta = resp[i].PointTimestamp - pricesReq[i].Timestamp
+				}
+			}(group)
+		}
+		wg.Wait()
+
+		logger.Infof("GetHistoricalPrices successfully")
+
+		return resp, http.StatusOK, nil
+	})
+}
+
+func parseGetHistoricalPricesRequest(
+	c *gin.Context,
+	logger *logging.Logger,
+) (pricesReq []HistoricalPriceReq, err error) {
+	if err = c.ShouldBindJSON(&pricesReq); err != nil {
+		logger.Errorf("Can't parse request body. %v", err)
+		return nil, fmt.Errorf("request body must be JSON array of {coin, timestamp, conversion} objects")
+	}
+
+	if len(pricesReq) == 0 {
+		return nil, fmt.Errorf("request body is empty")
+	}
+	if len(pricesReq) > maxHistoricalPrices {
+		return nil, fmt.Errorf("too many pairs: %d, max is %d", len(pricesReq), maxHistoricalPrices)
+	}
+
+	now := time.Now().Unix()
+	for i := range pricesReq {
+		pricesReq[i].CoinShort = strings.TrimSpace(pricesReq[i].CoinShort)
+		if pricesReq[i].CoinShort == "" {
+			return nil, httperror.FieldError(fmt.Sprintf("[%d].coin", i), "coin is required")
+		}
+		pricesReq[i].ConvCurr = normalizeConvCurr(pricesReq[i].ConvCurr)
+		if pricesReq[i].Timestamp >= unixMillisThreshold {
+			pricesReq[i].Timestamp /= 1000
+		}
+		if pricesReq[i].Timestamp <= 0 || pricesReq[i].Timestamp > now {
+			return nil, httperror.FieldError(fmt.Sprintf("[%d].timestamp", i), "timestamp must be in the past")
+		}
+	}
+
+	return pricesReq, nil
+}
+
+// groupHistoricalPrices groups request pairs by coin, conversion currency and UTC day.
+// Chart of one day has 5 minute or hourly points, while charts over 90 days have daily ones.
+func groupHistoricalPrices(pricesReq []HistoricalPriceReq) []*historicalPricesGroup {
+	const day = int64(24 * time.Hour / time.Second)
+
+	groups := make([]*historicalPricesGroup, 0)
+	byKey := make(map[string]*historicalPricesGroup)
+	for i, req := range pricesReq {
+		key := fmt.Sprintf("%s_%s_%d", req.CoinShort, req.ConvCurr, req.Timestamp/day)
+		group, found := byKey[key]
+		if !found {
+			group = &historicalPricesGroup{
+				coinShort: req.CoinShort,
+				convCurr:  req.ConvCurr,
+				from:      req.Timestamp,
+				to:        req.Timestamp,
+			}
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+		group.items = append(group.items, i)
+		if req.Timestamp < group.from {
+			group.from = req.Timestamp
+		}
+		if req.Timestamp > group.to {
+			group.to = req.Timestamp
+		}
+	}
+	return groups
+}
+
+// historicalPricesRange widens [from, to] so that the first and the last requested moments
+// have chart points on both sides. Coingecko returns daily points for ranges above 90 days,
+// hourly for ranges above 1 day and 5 minute points otherwise.
+func historicalPricesRange(from, to int64) (int64, int64) {
+	const day = int64(24 * time.Hour / time.Second)
+
+	pad := int64(10 * time.Minute / time.Second)
+	switch span := to - from; {
+	case span > 90*day:
+		pad = day
+	case span > day:
+		pad = int64(time.Hour / time.Second)
+	}
+
+	from, to = from-pad, to+pad
+	if now := time.Now().Unix(); to > now {
+		to = now
+	}
+	return from, to
+}
+
+// nearestChartPoint returns the point of time sorted chart closest to ts (milliseconds).
+func nearestChartPoint(points [][]float64, ts int64) []float64 {
+	i := sort.Search(len(points), func(i int) bool { return int64(points[i][0]) >= ts })
+	if i == len(points) {
+		return points[len(points)-1]
+	}
+	if i > 0 && ts-int64(points[i-1][0]) <= int64(points[i][0])-ts {
+		return points[i-1]
+	}
+	return points[i]
+}
@@ -0,0 +1,21 @@
+package coingeckometrics
+
+import (
+	"net/http"
+
+	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/networks"
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNetworks список поддерживаемых сетей и допустимых значений параметра network
+func GetNetworks(networkRegistry *networks.Registry, logger *logging.Logger) func(c *gin.Context) {
+	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
+		logger.Infof("Start GetNetworks...")
+
+		return networkRegistry.Networks(c.Request.Context()), http.StatusOK, nil
+	})
+}
@@ -13,8 +13,9 @@ import (
 
 func PingCoinGecko(coingeckoProvider *coingeckoprovider.Provider, logger *logging.Logger) func(c *gin.Context) {
 	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
 		logger.Infof("Start PingCoinGecko...")
-		pingRes, err := coingeckoProvider.PingCoinGeckoApi(c)
+		pingRes, err := coingeckoProvider.PingCoinGeckoApi(c.Request.Context())
 		if err != nil {
 			return nil, http.StatusInternalServerError, fmt.Errorf("internal server error")
 		}
@@ -3,10 +3,15 @@ package coingeckometrics
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"external-metrics/metrics/models"
 	coingeckoprovider "external-metrics/pkg/coingecko"
 	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/networks"
+	"external-metrics/pkg/tokenlist"
+	"external-metrics/pkg/tools/decimal"
+	"external-metrics/pkg/tools/evmaddress"
 	"external-metrics/pkg/tools/logging"
 
 	"github.com/gin-gonic/gin"
@@ -16,11 +21,20 @@ type GetDefiTokenInfoReq struct {
 	ConvCurr        string `form:"conversion"`
 	Network         string `form:"network" binding:"required"`
 	ContractAddress string `form:"address" binding:"required"`
+
+	convCurrs []string
 }
 
 // GetDefiTokenInfo получение информации о токене по его адресу
-func GetDefiTokenInfo(coingeckoProvider *coingeckoprovider.Provider, logger *logging.Logger) func(c *gin.Context) {
+// conversion может содержать несколько валют через запятую, как в GetCoinInfo
+func GetDefiTokenInfo(
+	coingeckoProvider *coingeckoprovider.Provider,
+	networkRegistry *networks.Registry,
+	tokens *tokenlist.Registry,
+	logger *logging.Logger,
+) func(c *gin.Context) {
 	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
 		logger.Infof("Start GetDefiTokenInfo...")
 
 		defiTokenInfoReq, isErrored := parseGetDefiTokenInfoRequest(c, logger)
@@ -28,8 +42,23 @@ func GetDefiTokenInfo(coingeckoProvider *coingeckoprovider.Provider, logger *log
 			return nil, http.StatusBadRequest, fmt.Errorf("query args parsing error")
 		}
 
+		if err := validateConvCurrs(c, coingeckoProvider, logger, "conversion", defiTokenInfoReq.convCurrs); err != nil {
+			return nil, httperror.Status(err, http.StatusBadRequest), err
+		}
+
+		network, err := networkRegistry.Resolve(c.Request.Context(), defiTokenInfoReq.Network)
+		if err != nil {
+			return nil, http.StatusBadRequest, httperror.FieldError("network", err.Error())
+		}
+		defiTokenInfoReq.Network = network.Platform
+
+		defiTokenInfoReq.ContractAddress, err = normalizeContractAddress(network, defiTokenInfoReq.ContractAddress)
+		if err != nil {
+			return nil, http.StatusBadRequest, httperror.FieldError("address", err.Error())
+		}
+
 		logger.Infof("Parse request successfully")
-		logger.Debug(
+		logger.Debugf(
 			"Request is network: %s, contractAddress: %s, convCurr: %s",
 			defiTokenInfoReq.Network,
 			defiTokenInfoReq.ContractAddress,
@@ -37,7 +66,7 @@ func GetDefiTokenInfo(coingeckoProvider *coingeckoprovider.Provider, logger *log
 		)
 
 		cgDefiCoinInfo, err := coingeckoProvider.GetDefiTokenInfo(
-			c,
+			c.Request.Context(),
 			defiTokenInfoReq.Network,
 			defiTokenInfoReq.ContractAddress,
 		)
@@ -58,13 +87,57 @@ func GetDefiTokenInfo(coingeckoProvider *coingeckoprovider.Provider, logger *log
 			)
 		}
 
+		marketData := cgDefiCoinInfo.MarketData
+		convCurr := defiTokenInfoReq.ConvCurr
 		defiCoinInfoResp := &models.CoinGeckoDefiTokenInfoResp{
-			Name:   cgDefiCoinInfo.Name,
-			Symbol: cgDefiCoinInfo.Symbol,
-			Icons:  models.CoinGeckoIconsResp(cgDefiCoinInfo.Icons),
+			ID:              cgDefiCoinInfo.ID,
+			ContractAddress: displayContractAddress(defiTokenInfoReq.ContractAddress),
+			Name:            cgDefiCoinInfo.Name,
+			Symbol:          cgDefiCoinInfo.Symbol,
+			Icons:           models.CoinGeckoIconsResp(cgDefiCoinInfo.Icons),
+			MarketCap:       marketData.MarketCap[convCurr],
+			Volume24:        marketData.TotalVolume[convCurr],
+			PriceChangePercentage: map[string]decimal.Decimal{
+				"1h":   marketData.PriceChangePercentage1hInCurrency[convCurr],
+				"24h":  marketData.PriceChangePercentage24hInCurrency[convCurr],
+				"7d":   marketData.PriceChangePercentage7dInCurrency[convCurr],
+				"14d":  marketData.PriceChangePercentage14dInCurrency[convCurr],
+				"30d":  marketData.PriceChangePercentage30dInCurrency[convCurr],
+				"200d": marketData.PriceChangePercentage200dInCurrency[convCurr],
+				"1y":   marketData.PriceChangePercentage1yInCurrency[convCurr],
+			},
+			CirculatingSupply: marketData.CirculatingSupply,
+			TotalSupply:       marketData.TotalSupply,
+			MaxSupply:         marketData.MaxSupply,
+			Platforms:         make(map[string]string),
+		}
+
+		// the requested network is dropped from other platforms addresses
+		for platform, address := range cgDefiCoinInfo.Platforms {
+			if platform != "" && address != "" && platform != defiTokenInfoReq.Network {
+				defiCoinInfoResp.Platforms[platform] = displayContractAddress(address)
+			}
+		}
+		if detail, found := cgDefiCoinInfo.DetailPlatforms[defiTokenInfoReq.Network]; found {
+			defiCoinInfoResp.Decimals = detail.DecimalPlace
+		}
+
+		// our token lists override coingecko logo and decimals
+		if network.ChainID != nil {
+			if token, found := tokens.Lookup(*network.ChainID, defiTokenInfoReq.ContractAddress); found {
+				if token.LogoURI != "" {
+					defiCoinInfoResp.Icons = models.CoinGeckoIconsResp{
+						Thumb: token.LogoURI,
+						Small: token.LogoURI,
+						Large: token.LogoURI,
+					}
+				}
+				decimals := token.Decimals
+				defiCoinInfoResp.Decimals = &decimals
+			}
 		}
 
-		if price, found := cgDefiCoinInfo.MarketData.CurrentPrice[defiTokenInfoReq.ConvCurr]; found {
+		if price, found := marketData.CurrentPrice[convCurr]; found {
 			defiCoinInfoResp.Price = price
 		} else {
 			logger.Infof(
@@ -77,6 +150,18 @@ func GetDefiTokenInfo(coingeckoProvider *coingeckoprovider.Provider, logger *log
 			)
 		}
 
+		if len(defiTokenInfoReq.convCurrs) > 1 {
+			defiCoinInfoResp.Currencies = make(map[string]models.CurrencyMarketDataResp, len(defiTokenInfoReq.convCurrs))
+			for _, convCurr := range defiTokenInfoReq.convCurrs {
+				defiCoinInfoResp.Currencies[convCurr] = models.CurrencyMarketDataResp{
+					Price:             marketData.CurrentPrice[convCurr],
+					MarketCap:         marketData.MarketCap[convCurr],
+					Volume24:          marketData.TotalVolume[convCurr],
+					PriceChange24Perc: marketData.PriceChangePercentage24hInCurrency[convCurr],
+				}
+			}
+		}
+
 		logger.Infof("GetDefiTokenInfo successfully")
 
 		return defiCoinInfoResp, http.StatusOK, nil
@@ -93,8 +178,25 @@ func parseGetDefiTokenInfoRequest(
 		logger.Errorf("Missing parameter(s) in url. %v", err)
 		return
 	}
-	if defiTokenInfoReq.ConvCurr == "" {
-		defiTokenInfoReq.ConvCurr = "usd"
-	}
+	defiTokenInfoReq.convCurrs = splitConvCurrs(defiTokenInfoReq.ConvCurr)
+	defiTokenInfoReq.ConvCurr = defiTokenInfoReq.convCurrs[0]
 	return defiTokenInfoReq, false
 }
+
+// normalizeContractAddress validates contract address of EVM network and lower-cases it
+// for coingecko requests and cache keys. Addresses of other networks are returned as is.
+func normalizeContractAddress(network *models.NetworkResp, address string) (string, error) {
+	address = strings.TrimSpace(address)
+	if !network.EVM {
+		return address, nil
+	}
+	return evmaddress.Normalize(address)
+}
+
+// displayContractAddress returns EVM address in EIP-55 checksum form, other addresses as is.
+func displayContractAddress(address string) string {
+	if evmaddress.IsAddress(address) {
+		return evmaddress.Checksum(address)
+	}
+	return address
+}
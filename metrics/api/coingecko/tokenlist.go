@@ -0,0 +1,83 @@
+package coingeckometrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	coingeckoprovider "external-metrics/pkg/coingecko"
+	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/networks"
+	"external-metrics/pkg/tokenlist"
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTokenList объединенный список токенов в формате Uniswap token list
+// с текущими ценами в extensions.price (валюта в extensions.priceCurrency).
+// Ответ не оборачивается в BaseResponse, чтобы список читали кошельки и dapps.
+func GetTokenList(
+	coingeckoProvider *coingeckoprovider.Provider,
+	networkRegistry *networks.Registry,
+	tokens *tokenlist.Registry,
+	logger *logging.Logger,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		logger := logging.FromContext(c.Request.Context(), logger)
+		logger.Infof("Start GetTokenList...")
+
+		convCurr := normalizeConvCurr(c.Query("conversion"))
+		if err := validateConvCurr(c, coingeckoProvider, logger, "conversion", convCurr); err != nil {
+			httperror.Abort(c, httperror.Status(err, http.StatusBadRequest), err)
+			return
+		}
+
+		list := tokens.List()
+
+		// tokens indexes by chain id
+		byChain := make(map[int64][]int)
+		for i, token := range list.Tokens {
+			byChain[token.ChainID] = append(byChain[token.ChainID], i)
+		}
+
+		for chainID, indexes := range byChain {
+			network, err := networkRegistry.Resolve(c.Request.Context(), strconv.FormatInt(chainID, 10))
+			if err != nil {
+				logger.Errorf("Can't resolve network of chain %d, tokens are listed without prices: %v", chainID, err)
+				continue
+			}
+
+			addresses := make([]string, 0, len(indexes))
+			for _, i := range indexes {
+				addresses = append(addresses, strings.ToLower(list.Tokens[i].Address))
+			}
+			prices, failed, err := coingeckoProvider.GetTokenPrices(c.Request.Context(), network.Platform, addresses, []string{convCurr})
+			if err != nil {
+				logger.Errorf("Can't get token prices for %s, tokens are listed without prices: %v", network.Platform, err)
+				continue
+			}
+			if len(failed) > 0 {
+				logger.Errorf("Can't get prices of %d tokens of %s, they are listed without prices", len(failed), network.Platform)
+			}
+
+			for _, i := range indexes {
+				price, found := prices[strings.ToLower(list.Tokens[i].Address)][convCurr]
+				if !found {
+					continue
+				}
+				extensions := make(map[string]interface{}, len(list.Tokens[i].Extensions)+2)
+				for key, value := range list.Tokens[i].Extensions {
+					extensions[key] = value
+				}
+				extensions["price"] = price
+				extensions["priceCurrency"] = convCurr
+				list.Tokens[i].Extensions = extensions
+			}
+		}
+
+		logger.Infof("GetTokenList successfully")
+
+		httperror.CacheableJSON(c, http.StatusOK, list)
+	}
+}
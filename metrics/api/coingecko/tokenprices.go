@@ -0,0 +1,143 @@
+package coingeckometrics
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"external-metrics/metrics/models"
+	coingeckoprovider "external-metrics/pkg/coingecko"
+	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/networks"
+	"external-metrics/pkg/tools/decimal"
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTokenPricesAddresses limits number of contract addresses in one request.
+const maxTokenPricesAddresses = 1000
+
+type GetTokenPricesReq struct {
+	ConvCurr  string `form:"conversion"`
+	Network   string `form:"network" binding:"required"`
+	Addresses string `form:"addresses" binding:"required"`
+
+	convCurrs []string
+	addresses []string
+}
+
+// GetTokenPrices получение цен набора токенов сети по адресам контрактов
+func GetTokenPrices(
+	coingeckoProvider *coingeckoprovider.Provider,
+	networkRegistry *networks.Registry,
+	logger *logging.Logger,
+) func(c *gin.Context) {
+	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
+		logger.Infof("Start GetTokenPrices...")
+
+		tokenPricesReq, err := parseGetTokenPricesRequest(c, logger)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+
+		if err := validateConvCurrs(c, coingeckoProvider, logger, "conversion", tokenPricesReq.convCurrs); err != nil {
+			return nil, httperror.Status(err, http.StatusBadRequest), err
+		}
+
+		network, err := networkRegistry.Resolve(c.Request.Context(), tokenPricesReq.Network)
+		if err != nil {
+			return nil, http.StatusBadRequest, httperror.FieldError("network", err.Error())
+		}
+		tokenPricesReq.Network = network.Platform
+
+		// normalized addresses are deduplicated, so that 0xabc... and 0xABC... are requested once
+		addresses := make([]string, 0, len(tokenPricesReq.addresses))
+		seen := make(map[string]bool, len(tokenPricesReq.addresses))
+		for _, address := range tokenPricesReq.addresses {
+			address, err = normalizeContractAddress(network, address)
+			if err != nil {
+				return nil, http.StatusBadRequest, httperror.FieldError("addresses", err.Error())
+			}
+			if !seen[address] {
+				seen[address] = true
+				addresses = append(addresses, address)
+			}
+		}
+		tokenPricesReq.addresses = addresses
+		if len(tokenPricesReq.addresses) > maxTokenPricesAddresses {
+			return nil, http.StatusBadRequest, httperror.FieldError(
+				"addresses", fmt.Sprintf("too many addresses: %d, max is %d", len(tokenPricesReq.addresses), maxTokenPricesAddresses),
+			)
+		}
+
+		logger.Infof("Parse request successfully")
+		logger.Debugf(
+			"Request is network: %s, %d addresses, convCurr: %s",
+			tokenPricesReq.Network,
+			len(tokenPricesReq.addresses),
+			tokenPricesReq.ConvCurr,
+		)
+
+		prices, failed, err := coingeckoProvider.GetTokenPrices(
+			c.Request.Context(),
+			tokenPricesReq.Network,
+			tokenPricesReq.addresses,
+			tokenPricesReq.convCurrs,
+		)
+		if err != nil {
+			logger.Errorf("Can't get coingecko token prices for %s", tokenPricesReq.Network)
+			return nil, http.StatusNotFound, fmt.Errorf(
+				"failed to get token prices for %s in %s", tokenPricesReq.Network, tokenPricesReq.ConvCurr,
+			)
+		}
+
+		tokenPricesResp := &models.TokenPricesResp{
+			Prices:  make(map[string]map[string]decimal.Decimal, len(prices)),
+			Missing: make([]string, 0),
+		}
+		for _, address := range failed {
+			tokenPricesResp.Failed = append(tokenPricesResp.Failed, displayContractAddress(address))
+		}
+		for _, address := range tokenPricesReq.addresses {
+			if price, found := prices[strings.ToLower(address)]; found {
+				tokenPricesResp.Prices[displayContractAddress(address)] = price
+			} else if !containsString(failed, address) {
+				tokenPricesResp.Missing = append(tokenPricesResp.Missing, displayContractAddress(address))
+			}
+		}
+		if len(failed) > 0 {
+			logger.Errorf("Can't get coingecko token prices of %d addresses for %s", len(failed), tokenPricesReq.Network)
+		}
+
+		logger.Infof("GetTokenPrices successfully")
+
+		return tokenPricesResp, http.StatusOK, nil
+	})
+}
+
+func parseGetTokenPricesRequest(
+	c *gin.Context,
+	logger *logging.Logger,
+) (tokenPricesReq GetTokenPricesReq, err error) {
+	if err = c.ShouldBindQuery(&tokenPricesReq); err != nil {
+		logger.Errorf("Missing parameter(s) in url. %v", err)
+		return tokenPricesReq, fmt.Errorf("query args parsing error")
+	}
+
+	tokenPricesReq.convCurrs = splitConvCurrs(tokenPricesReq.ConvCurr)
+	tokenPricesReq.ConvCurr = strings.Join(tokenPricesReq.convCurrs, ",")
+
+	for _, address := range strings.Split(tokenPricesReq.Addresses, ",") {
+		address = strings.TrimSpace(address)
+		if address != "" {
+			tokenPricesReq.addresses = append(tokenPricesReq.addresses, address)
+		}
+	}
+	if len(tokenPricesReq.addresses) == 0 {
+		return tokenPricesReq, httperror.FieldError("addresses", "addresses are required")
+	}
+
+	return tokenPricesReq, nil
+}
@@ -0,0 +1,82 @@
+package statusmetrics
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"external-metrics/config"
+	"external-metrics/metrics/models"
+	coingeckoprovider "external-metrics/pkg/coingecko"
+	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz liveness probe: отвечает, пока процесс обслуживает запросы
+func Healthz(logger *logging.Logger) func(c *gin.Context) {
+	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		return gin.H{"status": "ok"}, http.StatusOK, nil
+	})
+}
+
+// Readyz readiness probe: конфиг загружен, список монет получен, кэш доступен.
+// Не ходит в coingecko, поэтому недоступность coingecko не выводит инстанс из балансировки.
+func Readyz(
+	coingeckoProvider *coingeckoprovider.Provider,
+	cfg *config.Config,
+	logger *logging.Logger,
+) func(c *gin.Context) {
+	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
+		readiness := &models.ReadinessResp{Ready: true}
+
+		check := func(name string, err error) {
+			checkResp := models.ReadinessCheckResp{Name: name, OK: err == nil}
+			if err != nil {
+				checkResp.Error = err.Error()
+				readiness.Ready = false
+			}
+			readiness.Checks = append(readiness.Checks, checkResp)
+		}
+
+		var err error
+		if cfg == nil || cfg.Coingecko.APIAddress == "" {
+			err = fmt.Errorf("coingecko api address is not configured")
+		}
+		check("config", err)
+
+		err = nil
+		if !coingeckoProvider.CoinIndexWarm() {
+			err = fmt.Errorf("coins list is not loaded yet")
+		}
+		check("coin_index", err)
+
+		check("cache", coingeckoProvider.CheckCache())
+
+		if !readiness.Ready {
+			logger.Infof("Not ready: %+v", readiness.Checks)
+			return readiness, http.StatusServiceUnavailable, fmt.Errorf("not ready")
+		}
+		return readiness, http.StatusOK, nil
+	})
+}
+
+// GetStatus сводка по состоянию внешних API за последние запросы
+func GetStatus(
+	coingeckoProvider *coingeckoprovider.Provider,
+	startedAt time.Time,
+	logger *logging.Logger,
+) func(c *gin.Context) {
+	return httperror.ErrorWrapper(logger, func(c *gin.Context) (interface{}, int, error) {
+		logger := logging.FromContext(c.Request.Context(), logger)
+		logger.Infof("Start GetStatus...")
+
+		return &models.StatusResp{
+			StartedAt: startedAt.Unix(),
+			Uptime:    int64(time.Since(startedAt).Seconds()),
+			Upstreams: []models.UpstreamStatusResp{coingeckoProvider.Status()},
+		}, http.StatusOK, nil
+	})
+}
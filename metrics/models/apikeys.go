@@ -0,0 +1,12 @@
+package models
+
+// /admin/usage
+type APIKeyUsageResp struct {
+	Name          string `json:"name"`
+	RatePerMinute int    `json:"rate_per_minute"`     // Лимит запросов в минуту, 0 - без лимита
+	DailyQuota    int    `json:"daily_quota"`         // Квота запросов в сутки (UTC), 0 - без квоты
+	Today         int    `json:"today"`               // Запросов за текущие сутки
+	Total         int64  `json:"total"`               // Запросов с запуска
+	Rejected      int64  `json:"rejected"`            // Отклонено по лимиту или квоте с запуска
+	LastUsed      *int64 `json:"last_used,omitempty"` // Время последнего запроса (unix seconds)
+}
@@ -1,29 +1,48 @@
 package models
 
+import "external-metrics/pkg/tools/decimal"
+
 type CoinGeckoPingResp struct {
 	GeckoSays string `json:"gecko_says"`
 }
 
+// Рыночные данные монеты в одной валюте
+type CurrencyMarketDataResp struct {
+	Price             decimal.Decimal `json:"price"`                       // Курс монеты
+	MarketCap         decimal.Decimal `json:"market_cap"`                  // Капитализация
+	Volume24          decimal.Decimal `json:"volume_24h"`                  // Объем торгов за 24 часа
+	PriceChange24Perc decimal.Decimal `json:"price_change_percentage_24h"` // Изменение за 24 часа в процентах
+}
+
 // /coins/markets
 type CoinInfoResp struct {
 	Name                string            `json:"name"`                        // Имя
 	Image               string            `json:"image"`                       // Изображение
-	CurrentPrice        float32           `json:"current_price"`               // Курс монеты в валюте conversion
-	PriceChange24       float32           `json:"price_change_24h"`            // Изменение за 24 часа в валюте conversion
-	PriceChange24Perc   float32           `json:"price_change_percentage_24h"` // Изменение за 24 часа в процентах
-	MarketCap           float32           `json:"market_cap"`                  //Капитализация в валюте conversion
-	CirculatingSupply   float32           `json:"circulating_supply"`          // Монет в обороте
-	TotalSupply         float32           `json:"total_supply"`                // Монет всего
-	MaxSupply           float32           `json:"max_supply"`                  // Монет всего
+	CurrentPrice        decimal.Decimal   `json:"current_price"`               // Курс монеты в валюте conversion
+	PriceChange24       decimal.Decimal   `json:"price_change_24h"`            // Изменение за 24 часа в валюте conversion
+	PriceChange24Perc   decimal.Decimal   `json:"price_change_percentage_24h"` // Изменение за 24 часа в процентах
+	MarketCap           decimal.Decimal   `json:"market_cap"`                  //Капитализация в валюте conversion
+	CirculatingSupply   decimal.Decimal   `json:"circulating_supply"`          // Монет в обороте
+	TotalSupply         decimal.Decimal   `json:"total_supply"`                // Монет всего
+	MaxSupply           decimal.Decimal   `json:"max_supply"`                  // Монет всего
 	Rank                int               `json:"market_cap_rank"`             // Ранг монеты
-	Ath                 float32           `json:"ath"`                         // Максимум за все время
-	Volume24            float32           `json:"volume_24h"`                  // Объем торгов за 24 часа в валюте conversion
-	MarketCapPercentage float32           `json:"market_cap_percentage"`       // Доля рынка в процентах
+	Ath                 decimal.Decimal   `json:"ath"`                         // Максимум за все время
+	Volume24            decimal.Decimal   `json:"volume_24h"`                  // Объем торгов за 24 часа в валюте conversion
+	MarketCapPercentage decimal.Decimal   `json:"market_cap_percentage"`       // Доля рынка в процентах
 	About               map[string]string `json:"about"`                       // Описание монеты на англ
+
+	Currencies map[string]CurrencyMarketDataResp `json:"currencies,omitempty"` // Данные по каждой валюте, если в conversion их несколько
 }
 
+// /coins/{id}/market_chart/range
+// Каждая точка серии - пара [timestamp(ms), value]
 type CoinGeckoCoinChartResp struct {
-	Prices [][]float64 `json:"prices"`
+	Prices       [][]float64 `json:"prices"`                  // Цена в валюте conversion
+	MarketCaps   [][]float64 `json:"market_caps,omitempty"`   // Капитализация в валюте conversion
+	TotalVolumes [][]float64 `json:"total_volumes,omitempty"` // Объем торгов в валюте conversion
+	BucketWidth  int64       `json:"bucket_width,omitempty"`  // Средний интервал между точками (ms) при прореживании
+	RangeStart   int64       `json:"range_start"`             // Начало запрошенного диапазона (unix seconds)
+	RangeEnd     int64       `json:"range_end"`               // Конец запрошенного диапазона (unix seconds)
 }
 
 type CoinGeckoIconsResp struct {
@@ -33,8 +52,64 @@ type CoinGeckoIconsResp struct {
 }
 
 type CoinGeckoDefiTokenInfoResp struct {
-	Name   string             `json:"name"`
-	Symbol string             `json:"symbol"`
-	Icons  CoinGeckoIconsResp `json:"image"` // Ссылки на изображения
-	Price  float32            `json:"current_price"`
+	ID                    string                     `json:"id"`               // Идентификатор монеты в coingecko
+	ContractAddress       string                     `json:"contract_address"` // Адрес контракта в сети network (EIP-55 для EVM сетей)
+	Name                  string                     `json:"name"`
+	Symbol                string                     `json:"symbol"`
+	Icons                 CoinGeckoIconsResp         `json:"image"` // Ссылки на изображения
+	Price                 decimal.Decimal            `json:"current_price"`
+	MarketCap             decimal.Decimal            `json:"market_cap"`              // Капитализация в валюте conversion
+	Volume24              decimal.Decimal            `json:"volume_24h"`              // Объем торгов за 24 часа в валюте conversion
+	PriceChangePercentage map[string]decimal.Decimal `json:"price_change_percentage"` // Изменение цены в процентах по окнам: 1h, 24h, 7d, 14d, 30d, 200d, 1y
+	CirculatingSupply     decimal.Decimal            `json:"circulating_supply"`      // Монет в обороте
+	TotalSupply           decimal.Decimal            `json:"total_supply"`            // Монет всего
+	MaxSupply             decimal.Decimal            `json:"max_supply"`              // Максимальное число монет
+	Decimals              *int                       `json:"decimals"`                // Число знаков после запятой у токена в сети network
+	Platforms             map[string]string          `json:"platforms"`               // Адреса контрактов токена в других сетях
+
+	Currencies map[string]CurrencyMarketDataResp `json:"currencies,omitempty"` // Данные по каждой валюте, если в conversion их несколько
+}
+
+// /token/prices
+type TokenPricesResp struct {
+	Prices  map[string]map[string]decimal.Decimal `json:"prices"`           // Цены по адресу контракта и валюте
+	Missing []string                              `json:"missing"`          // Адреса, для которых цена не найдена
+	Failed  []string                              `json:"failed,omitempty"` // Адреса, цены которых не удалось запросить
+}
+
+// /coins/{id}/history
+type CoinHistoryResp struct {
+	Name      string          `json:"name"`       // Имя
+	Date      string          `json:"date"`       // Дата в формате YYYY-MM-DD (UTC)
+	Price     decimal.Decimal `json:"price"`      // Курс монеты в валюте conversion на 00:00 UTC
+	MarketCap decimal.Decimal `json:"market_cap"` // Капитализация в валюте conversion
+	Volume24  decimal.Decimal `json:"volume_24h"` // Объем торгов за 24 часа в валюте conversion
+}
+
+// POST /prices/historical
+type HistoricalPriceResp struct {
+	Coin           string           `json:"coin"`                      // Монета из запроса
+	Conversion     string           `json:"conversion"`                // Валюта из запроса
+	Timestamp      int64            `json:"timestamp"`                 // Запрошенное время (unix seconds)
+	Price          *decimal.Decimal `json:"price,omitempty"`           // Курс в ближайшей точке графика
+	PointTimestamp int64            `json:"point_timestamp,omitempty"` // Время ближайшей точки графика (unix seconds)
+	Delta          int64            `json:"delta"`                     // point_timestamp - timestamp в секундах
+	Error          string           `json:"error,omitempty"`           // Причина, если цену получить не удалось
+}
+
+// /convert
+type ConvertStepResp struct {
+	From   string          `json:"from"`
+	To     string          `json:"to"`
+	Rate   decimal.Decimal `json:"rate"`   // Сколько to за 1 from
+	Source string          `json:"source"` // Эндпоинт coingecko, из которого получен курс
+}
+
+type ConvertResp struct {
+	From   string            `json:"from"`
+	To     string            `json:"to"`
+	Amount decimal.Decimal   `json:"amount"`
+	Rate   decimal.Decimal   `json:"rate"`   // Итоговый курс: сколько to за 1 from
+	Result decimal.Decimal   `json:"result"` // amount * rate
+	Path   []ConvertStepResp `json:"path"`   // Цепочка курсов через опорную валюту
 }
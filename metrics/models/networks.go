@@ -0,0 +1,16 @@
+package models
+
+type ExplorerResp struct {
+	Name       string `json:"name"`
+	APIAddress string `json:"api"`
+}
+
+// /networks
+type NetworkResp struct {
+	Platform string        `json:"platform"`           // Идентификатор сети в coingecko (asset platform)
+	ChainID  *int64        `json:"chain_id,omitempty"` // EVM chain id
+	Name     string        `json:"name"`
+	EVM      bool          `json:"evm"`                // Адреса контрактов сети - EVM адреса
+	Aliases  []string      `json:"aliases"`            // Допустимые значения параметра network
+	Explorer *ExplorerResp `json:"explorer,omitempty"` // Настроенный обозреватель блоков сети
+}
@@ -0,0 +1,35 @@
+package models
+
+// /readyz
+type ReadinessCheckResp struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"` // Причина, если проверка не пройдена
+}
+
+type ReadinessResp struct {
+	Ready  bool                 `json:"ready"`
+	Checks []ReadinessCheckResp `json:"checks"`
+}
+
+// Сводка по последним запросам к внешнему API
+type UpstreamStatusResp struct {
+	Name           string  `json:"name"`
+	Window         int     `json:"window"`                     // Сколько последних запросов учитывается в сводке
+	Calls          int     `json:"calls"`                      // Число последних запросов в сводке
+	SuccessRate    float64 `json:"success_rate"`               // Доля успешных запросов, 0..1
+	AvgLatencyMs   int64   `json:"avg_latency_ms"`             // Средняя задержка
+	P95LatencyMs   int64   `json:"p95_latency_ms"`             // 95-й перцентиль задержки
+	LastSuccess    *int64  `json:"last_success,omitempty"`     // Время последнего успешного запроса (unix seconds)
+	LastError      *int64  `json:"last_error,omitempty"`       // Время последней ошибки (unix seconds)
+	LastErrorClass string  `json:"last_error_class,omitempty"` // Класс последней ошибки: timeout, http_429, http_5xx...
+	CircuitState   string  `json:"circuit_state"`              // Состояние circuit breaker, none - breaker не используется
+	CoinIndexWarm  bool    `json:"coin_index_warm"`            // Загружен ли список монет
+}
+
+// /status
+type StatusResp struct {
+	StartedAt int64                `json:"started_at"` // Время запуска (unix seconds)
+	Uptime    int64                `json:"uptime"`     // Секунд с запуска
+	Upstreams []UpstreamStatusResp `json:"upstreams"`
+}
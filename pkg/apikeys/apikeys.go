@@ -0,0 +1,266 @@
+package apikeys
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"external-metrics/metrics/models"
+	"external-metrics/pkg/tools/logging"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Key is API key of a client with its limits.
+type Key struct {
+	Key           string `yaml:"key"`
+	Name          string `yaml:"name"`            // Client name shown in usage
+	RatePerMinute int    `yaml:"rate_per_minute"` // Requests per minute, 0 - unlimited
+	DailyQuota    int    `yaml:"daily_quota"`     // Requests per UTC day, 0 - unlimited
+	Admin         bool   `yaml:"admin"`           // Access to admin endpoints
+}
+
+// keysFile is format of hot-reloaded keys file.
+type keysFile struct {
+	Keys []Key `yaml:"keys"`
+}
+
+// Decision is result of Store.Allow.
+type Decision struct {
+	Key        *Key
+	Allowed    bool
+	Reason     string    // Why request is rejected
+	Limit      int       // Limit of the tightest window, 0 if key is unlimited
+	Remaining  int       // Requests left in the tightest window
+	Reset      time.Time // End of the tightest window
+	RetryAfter time.Duration
+}
+
+// usage is request accounting of one key.
+type usage struct {
+	minute      time.Time // Start of current minute window
+	minuteCount int
+	day         time.Time // Start of current UTC day
+	dayCount    int
+	total       int64
+	rejected    int64
+	lastUsed    time.Time
+}
+
+// Store keeps API keys from config and keys file and accounts their usage.
+type Store struct {
+	configKeys []Key
+	file       string
+	logger     *logging.Logger
+
+	mu          sync.Mutex
+	keys        map[string]*Key
+	usage       map[string]*usage // by key, kept across reloads
+	fileModTime time.Time
+}
+
+// NewStore returns store of configKeys and keys from file, if file is set.
+func NewStore(configKeys []Key, file string, logger *logging.Logger) (*Store, error) {
+	s := &Store{
+		configKeys: configKeys,
+		file:       file,
+		logger:     logger,
+		usage:      make(map[string]*usage),
+	}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Enabled reports whether any key is configured.
+func (s *Store) Enabled() bool {
+	return len(s.configKeys) > 0 || s.file != ""
+}
+
+// Reload rereads keys file. Keys of file override config keys with the same key.
+func (s *Store) Reload() error {
+	keys := make(map[string]*Key, len(s.configKeys))
+	for i := range s.configKeys {
+		key := s.configKeys[i]
+		keys[key.Key] = &key
+	}
+
+	var modTime time.Time
+	if s.file != "" {
+		info, err := os.Stat(s.file)
+		if err != nil {
+			return err
+		}
+		modTime = info.ModTime()
+
+		data, err := os.ReadFile(s.file)
+		if err != nil {
+			return err
+		}
+		var fileKeys keysFile
+		if err = yaml.UnmarshalStrict(data, &fileKeys); err != nil {
+			return fmt.Errorf("parse keys file %s: %w", s.file, err)
+		}
+		for i := range fileKeys.Keys {
+			key := fileKeys.Keys[i]
+			keys[key.Key] = &key
+		}
+	}
+
+	for k, key := range keys {
+		if k == "" {
+			return fmt.Errorf("empty api key %q", key.Name)
+		}
+		if key.Name == "" {
+			key.Name = maskKey(k)
+		}
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.fileModTime = modTime
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Watch reloads keys file every interval when its modification time changes, until ctx is done.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	if s.file == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(s.file)
+		if err != nil {
+			s.logger.Errorf("Can't stat api keys file %s: %v", s.file, err)
+			continue
+		}
+		s.mu.Lock()
+		changed := !info.ModTime().Equal(s.fileModTime)
+		s.mu.Unlock()
+		if !changed {
+			continue
+		}
+
+		if err = s.Reload(); err != nil {
+			s.logger.Errorf("Can't reload api keys file, keeping previous keys: %v", err)
+			continue
+		}
+		s.logger.Infof("API keys reloaded from %s", s.file)
+	}
+}
+
+// Allow looks key up and accounts request against its per-minute rate and daily quota.
+func (s *Store) Allow(apiKey string, now time.Time) Decision {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, found := s.keys[apiKey]
+	if !found {
+		return Decision{Reason: "invalid api key"}
+	}
+
+	u, found := s.usage[apiKey]
+	if !found {
+		u = &usage{}
+		s.usage[apiKey] = u
+	}
+	minute := now.Truncate(time.Minute)
+	if !u.minute.Equal(minute) {
+		u.minute, u.minuteCount = minute, 0
+	}
+	day := now.UTC().Truncate(24 * time.Hour)
+	if !u.day.Equal(day) {
+		u.day, u.dayCount = day, 0
+	}
+
+	decision := Decision{Key: key, Allowed: true}
+	windows := []struct {
+		limit, count int
+		reset        time.Time
+		reason       string
+	}{
+		{key.RatePerMinute, u.minuteCount, minute.Add(time.Minute), "rate limit exceeded"},
+		{key.DailyQuota, u.dayCount, day.Add(24 * time.Hour), "daily quota exceeded"},
+	}
+	for _, window := range windows {
+		if window.limit <= 0 {
+			continue
+		}
+		remaining := window.limit - window.count
+		if remaining <= 0 {
+			decision.Allowed = false
+			decision.Reason = window.reason
+			decision.Limit, decision.Remaining, decision.Reset = window.limit, 0, window.reset
+			decision.RetryAfter = window.reset.Sub(now)
+			break
+		}
+		// the tightest window is reported in headers
+		if decision.Limit == 0 || remaining-1 < decision.Remaining {
+			decision.Limit, decision.Remaining, decision.Reset = window.limit, remaining-1, window.reset
+		}
+	}
+
+	if !decision.Allowed {
+		u.rejected++
+		return decision
+	}
+	u.minuteCount++
+	u.dayCount++
+	u.total++
+	u.lastUsed = now
+
+	return decision
+}
+
+// Usage returns request accounting of every key sorted by name.
+func (s *Store) Usage(now time.Time) []models.APIKeyUsageResp {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	day := now.UTC().Truncate(24 * time.Hour)
+	usages := make([]models.APIKeyUsageResp, 0, len(s.keys))
+	for apiKey, key := range s.keys {
+		usageResp := models.APIKeyUsageResp{
+			Name:          key.Name,
+			RatePerMinute: key.RatePerMinute,
+			DailyQuota:    key.DailyQuota,
+		}
+		if u, found := s.usage[apiKey]; found {
+			if u.day.Equal(day) {
+				usageResp.Today = u.dayCount
+			}
+			usageResp.Total = u.total
+			usageResp.Rejected = u.rejected
+			if !u.lastUsed.IsZero() {
+				lastUsed := u.lastUsed.Unix()
+				usageResp.LastUsed = &lastUsed
+			}
+		}
+		usages = append(usages, usageResp)
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Name < usages[j].Name })
+
+	return usages
+}
+
+// maskKey returns key with all but first 4 characters hidden.
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return key[:4] + "****"
+}
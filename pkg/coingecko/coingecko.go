@@ -9,11 +9,19 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"external-metrics/metrics/models"
+	"external-metrics/pkg/ratelimit"
+	"external-metrics/pkg/tools/cachecontrol"
+	"external-metrics/pkg/tools/decimal"
 	"external-metrics/pkg/tools/logging"
 
+	"go.opentelemetry.io/otel/semconv"
+
 	cache "github.com/patrickmn/go-cache"
 	coingecko "github.com/superoo7/go-gecko/v3"
 )
@@ -23,6 +31,11 @@ type Provider struct {
 	apiAddress string
 	logger     *logging.Logger
 	memCache   *cache.Cache
+	limiter    *ratelimit.Limiter // outbound rate limit, nil if disabled
+
+	coinIndexMu       sync.Mutex // serializes coin index loads
+	coinIndexLoadedAt int64      // unix nano time of last successful coin index load, 0 before
+	recentCalls       callLog
 }
 
 // coin struct for cache store
@@ -40,20 +53,58 @@ func NewProvider(apiAddress string, logger *logging.Logger) (*Provider, error) {
 	}, nil
 }
 
+// SetRateLimit limits outbound requests to perMinute on average and up to burst at once.
+// perMinute of 0 disables the limit.
+func (p *Provider) SetRateLimit(perMinute int, burst int) {
+	if perMinute <= 0 {
+		p.limiter = nil
+		return
+	}
+	p.limiter = ratelimit.New(perMinute, burst)
+}
+
+// waitRateLimit blocks until outbound rate limit allows request or ctx is done.
+func (p *Provider) waitRateLimit(ctx context.Context) error {
+	if p.limiter == nil {
+		return nil
+	}
+	start := time.Now()
+	defer func() { upstreamRateLimitWait.Observe(time.Since(start).Seconds(), upstreamName) }()
+
+	for {
+		allowed, wait := p.limiter.Allow(upstreamName, time.Now())
+		if allowed {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// log returns request-scoped logger of ctx or provider logger.
+func (p *Provider) log(ctx context.Context) *logging.Logger {
+	return logging.FromContext(ctx, p.logger)
+}
+
 func (p *Provider) PingCoinGeckoApi(ctx context.Context) (*models.CoinGeckoPingResp, error) {
-	p.logger.Infof("Start PingCoinGeckoApi provider method...")
+	p.log(ctx).Infof("Start PingCoinGeckoApi provider method...")
 
 	respBody, err := p.Do(ctx, "GET", "/ping", nil)
 	if err != nil {
-		p.logger.Errorf("Can't ping coingecko at %s: %v", p.apiAddress, err)
+		p.log(ctx).Errorf("Can't ping coingecko at %s: %v", p.apiAddress, err)
 		return nil, err
 	}
 
-	p.logger.Infof("Get success response")
+	p.log(ctx).Infof("Get success response")
 
 	res := &models.CoinGeckoPingResp{}
 	if err = json.Unmarshal(respBody, res); err != nil {
-		p.logger.Errorf("ReqToCoinGeckoApi: Can not unmarshal coingecko response ping body %s: %v", p.apiAddress, err)
+		p.log(ctx).Errorf("ReqToCoinGeckoApi: Can not unmarshal coingecko response ping body %s: %v", p.apiAddress, err)
 		return res, err
 	}
 
@@ -65,26 +116,147 @@ func (p *Provider) GetDefiTokenInfo(
 	network string,
 	contractAddress string,
 ) (*CoinGeckoDefiCoinInfo, error) {
-	p.logger.Infof("Start GetDefiTokenInfo provider method...")
+	p.log(ctx).Infof("Start GetDefiTokenInfo provider method...")
 
 	requestURL := fmt.Sprintf("/coins/%s/contract/%s", network, contractAddress)
 	respBody, err := p.Do(ctx, "GET", requestURL, nil)
 	if err != nil {
-		p.logger.Errorf("Can't GET coin info by contract address from coingecko. URL: %s: %v", requestURL, err)
+		p.log(ctx).Errorf("Can't GET coin info by contract address from coingecko. URL: %s: %v", requestURL, err)
 
 		return nil, err
 	}
 
-	p.logger.Infof("Get success response")
+	p.log(ctx).Infof("Get success response")
 
 	var coinDefiInfo CoinGeckoDefiCoinInfo
 	if err = json.Unmarshal(respBody, &coinDefiInfo); err != nil {
-		p.logger.Errorf("Can't unmarshal coingecko defiCoinInfo body. URL: %s: %v", requestURL, err)
+		p.log(ctx).Errorf("Can't unmarshal coingecko defiCoinInfo body. URL: %s: %v", requestURL, err)
 		return nil, err
 	}
 	return &coinDefiInfo, nil
 }
 
+const (
+	// tokenPriceChunkSize is max number of contract addresses in one /simple/token_price request.
+	tokenPriceChunkSize = 50
+	// tokenPriceConcurrency limits chunks requested at once.
+	tokenPriceConcurrency = 4
+	// tokenPriceTTL is cache lifetime of token price, including "not found".
+	tokenPriceTTL = time.Minute
+)
+
+// GetTokenPrices returns prices of tokens by their contract addresses in network.
+// Addresses are requested by chunks of tokenPriceChunkSize, result is keyed by
+// lower-cased address (coingecko lower-cases them too), then by currency. Tokens unknown to coingecko are missing in result.
+// Prices are cached by address for tokenPriceTTL, only uncached addresses are requested.
+// Addresses of failed chunks are returned in failed, error is returned only if every chunk failed.
+func (p *Provider) GetTokenPrices(
+	ctx context.Context,
+	network string,
+	contractAddresses []string,
+	convCurrs []string,
+) (prices map[string]map[string]decimal.Decimal, failed []string, err error) {
+	p.log(ctx).Infof("Start GetTokenPrices provider method...")
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		lastErr error
+	)
+	prices = make(map[string]map[string]decimal.Decimal, len(contractAddresses))
+	uncached := make([]string, 0, len(contractAddresses))
+	for _, address := range contractAddresses {
+		res, found := p.cacheGet(ctx, "token_price", tokenPriceCacheKey(network, address, convCurrs))
+		if !found {
+			uncached = append(uncached, address)
+			continue
+		}
+		if price := res.(map[string]decimal.Decimal); len(price) > 0 {
+			prices[strings.ToLower(address)] = price
+		}
+	}
+
+	sem := make(chan struct{}, tokenPriceConcurrency)
+	for start := 0; start < len(uncached); start += tokenPriceChunkSize {
+		end := start + tokenPriceChunkSize
+		if end > len(uncached) {
+			end = len(uncached)
+		}
+		chunk := uncached[start:end]
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			chunkPrices, err := p.getTokenPricesChunk(ctx, network, chunk, convCurrs)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, chunk...)
+				lastErr = err
+				return
+			}
+			for address, price := range chunkPrices {
+				prices[address] = price
+			}
+			for _, address := range chunk {
+				price := chunkPrices[strings.ToLower(address)]
+				if price == nil {
+					price = map[string]decimal.Decimal{}
+				}
+				p.cacheSet(ctx, tokenPriceCacheKey(network, address, convCurrs), price, tokenPriceTTL)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(contractAddresses) > 0 && len(failed) == len(contractAddresses) {
+		return nil, failed, lastErr
+	}
+	return prices, failed, nil
+}
+
+func tokenPriceCacheKey(network string, address string, convCurrs []string) string {
+	return fmt.Sprintf("%s_%s_%s_token_price", network, strings.ToLower(address), strings.Join(convCurrs, ","))
+}
+
+// getTokenPricesChunk requests prices of up to tokenPriceChunkSize addresses.
+func (p *Provider) getTokenPricesChunk(
+	ctx context.Context,
+	network string,
+	contractAddresses []string,
+	convCurrs []string,
+) (map[string]map[string]decimal.Decimal, error) {
+	params := url.Values{
+		"contract_addresses": {strings.Join(contractAddresses, ",")},
+		"vs_currencies":      {strings.Join(convCurrs, ",")},
+	}
+	requestURL := fmt.Sprintf("/simple/token_price/%s?", network) + params.Encode()
+	respBody, err := p.Do(ctx, "GET", requestURL, nil)
+	if err != nil {
+		p.log(ctx).Errorf("Can't GET token prices from coingecko. URL: %s: %v", requestURL, err)
+		return nil, err
+	}
+
+	p.log(ctx).Infof("Get success response")
+
+	var prices map[string]map[string]decimal.Decimal
+	if err = json.Unmarshal(respBody, &prices); err != nil {
+		p.log(ctx).Errorf("Can't unmarshal coingecko token prices body. URL: %s: %v", requestURL, err)
+		return nil, err
+	}
+	res := make(map[string]map[string]decimal.Decimal, len(prices))
+	for address, price := range prices {
+		res[strings.ToLower(address)] = price
+	}
+	return res, nil
+}
+
 func (p *Provider) GetCoinGeckoCoinChart(
 	ctx context.Context,
 	coinShort string,
@@ -92,7 +264,7 @@ func (p *Provider) GetCoinGeckoCoinChart(
 	rangeStart string,
 	rangeEnd string,
 ) (*models.CoinGeckoCoinChartResp, error) {
-	p.logger.Infof("Start GetCoinChart provider method...")
+	p.log(ctx).Infof("Start GetCoinChart provider method...")
 
 	coinId, _, err := p.GetCoinIDName(ctx, coinShort)
 	if err != nil {
@@ -107,26 +279,82 @@ func (p *Provider) GetCoinGeckoCoinChart(
 	requestURL := fmt.Sprintf("/coins/%s/market_chart/range?", coinId) + params.Encode()
 	respBody, err := p.Do(ctx, "GET", requestURL, nil)
 	if err != nil {
-		p.logger.Errorf("Can't GET coin chart from coingecko. URL: %s: %v", requestURL, err)
+		p.log(ctx).Errorf("Can't GET coin chart from coingecko. URL: %s: %v", requestURL, err)
 		return nil, err
 	}
 
-	p.logger.Infof("Get success response")
+	p.log(ctx).Infof("Get success response")
 
 	var coinChart models.CoinGeckoCoinChartResp
 	if err = json.Unmarshal(respBody, &coinChart); err != nil {
-		p.logger.Errorf("Can't unmarshal coingecko getCoinChart body. URL: %s: %v", requestURL, err)
+		p.log(ctx).Errorf("Can't unmarshal coingecko getCoinChart body. URL: %s: %v", requestURL, err)
 		return nil, err
 	}
 	return &coinChart, nil
 }
 
+const (
+	// coinHistoryTodayTTL is cache lifetime of history for the current day which is not final yet.
+	coinHistoryTodayTTL = 5 * time.Minute
+	// coinHistoryPastTTL is cache lifetime of history for past days. It is finite,
+	// so that cache of any coin on any date requested doesn't grow without bound.
+	coinHistoryPastTTL = 24 * time.Hour
+)
+
+// GetCoinHistory returns coin market data at 00:00 UTC of the date.
+// Past days never change, so their data is cached permanently.
+func (p *Provider) GetCoinHistory(
+	ctx context.Context,
+	coinShort string,
+	date time.Time,
+) (*CoinGeckoCoinHistory, error) {
+	p.log(ctx).Infof("Start GetCoinHistory provider method...")
+
+	coinId, _, err := p.GetCoinIDName(ctx, coinShort)
+	if err != nil {
+		return nil, err
+	}
+
+	date = date.UTC().Truncate(24 * time.Hour)
+	cacheKey := fmt.Sprintf("%s_history_%s", coinId, date.Format("2006-01-02"))
+	if res, found := p.cacheGet(ctx, "coin_history", cacheKey); found {
+		return res.(*CoinGeckoCoinHistory), nil
+	}
+
+	params := url.Values{
+		"date":         {date.Format("02-01-2006")},
+		"localization": {"false"},
+	}
+	requestURL := fmt.Sprintf("/coins/%s/history?", coinId) + params.Encode()
+	respBody, err := p.Do(ctx, "GET", requestURL, nil)
+	if err != nil {
+		p.log(ctx).Errorf("Can't GET coin history from coingecko. URL: %s: %v", requestURL, err)
+		return nil, err
+	}
+
+	p.log(ctx).Infof("Get success response")
+
+	var history CoinGeckoCoinHistory
+	if err = json.Unmarshal(respBody, &history); err != nil {
+		p.log(ctx).Errorf("Can't unmarshal coingecko coin history body. URL: %s: %v", requestURL, err)
+		return nil, err
+	}
+
+	ttl := coinHistoryPastTTL
+	if !date.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+		ttl = coinHistoryTodayTTL
+	}
+	p.cacheSet(ctx, cacheKey, &history, ttl)
+
+	return &history, nil
+}
+
 func (p *Provider) GetCoinInfo(
 	ctx context.Context,
 	coinShort string,
 	convCurr string,
 ) (*models.CoinInfoResp, error) {
-	p.logger.Infof("Starting GetCoinInfo provider method...")
+	p.log(ctx).Infof("Starting GetCoinInfo provider method...")
 
 	coinId, coinName, err := p.GetCoinIDName(ctx, coinShort)
 	if err != nil {
@@ -145,12 +373,12 @@ func (p *Provider) GetCoinInfo(
 	url := "/coins/markets?" + params.Encode()
 	respBody, err := p.Do(ctx, "GET", url, nil)
 	if err != nil {
-		p.logger.Errorf("Can't get coingecko response GET coins market. URL: %s: %v", url, err)
+		p.log(ctx).Errorf("Can't get coingecko response GET coins market. URL: %s: %v", url, err)
 		return &models.CoinInfoResp{}, err
 	}
 	var coinsInfo []models.CoinInfoResp
 	if err = json.Unmarshal(respBody, &coinsInfo); err != nil {
-		p.logger.Errorf("Can't unmarshal coingecko GET coins market resp. URL: %s: %v", url, err)
+		p.log(ctx).Errorf("Can't unmarshal coingecko GET coins market resp. URL: %s: %v", url, err)
 		return &models.CoinInfoResp{}, err
 	}
 
@@ -171,7 +399,7 @@ func (p *Provider) GetCoinInfo(
 	if err != nil {
 		return &coinsInfo[0], err
 	}
-	if coinMarketPercentage != 0 {
+	if !coinMarketPercentage.IsZero() {
 		coinsInfo[0].MarketCapPercentage = coinMarketPercentage
 	}
 
@@ -185,9 +413,58 @@ func (p *Provider) GetCoinInfo(
 	return &coinsInfo[0], nil
 }
 
+// GetCoinCurrenciesMarketData returns coin price, market cap, 24h volume and 24h change
+// for every currency of convCurrs with one /simple/price request.
+func (p *Provider) GetCoinCurrenciesMarketData(
+	ctx context.Context,
+	coinShort string,
+	convCurrs []string,
+) (map[string]models.CurrencyMarketDataResp, error) {
+	p.log(ctx).Infof("Start GetCoinCurrenciesMarketData provider method...")
+
+	coinId, _, err := p.GetCoinIDName(ctx, coinShort)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{
+		"ids":                 {coinId},
+		"vs_currencies":       {strings.Join(convCurrs, ",")},
+		"include_market_cap":  {"true"},
+		"include_24hr_vol":    {"true"},
+		"include_24hr_change": {"true"},
+	}
+	url := "/simple/price?" + params.Encode()
+	respBody, err := p.Do(ctx, "GET", url, nil)
+	if err != nil {
+		p.log(ctx).Errorf("Can't GET simple price from coingecko. URL: %s: %v", url, err)
+		return nil, err
+	}
+
+	p.log(ctx).Infof("Get success response")
+
+	var data map[string]map[string]decimal.Decimal
+	if err := json.Unmarshal(respBody, &data); err != nil {
+		p.log(ctx).Errorf("Can't unmarshal coingecko response GET simple price body for coinId: %s: %v ", coinId, err)
+		return nil, err
+	}
+
+	res := make(map[string]models.CurrencyMarketDataResp, len(convCurrs))
+	for _, convCurr := range convCurrs {
+		res[convCurr] = models.CurrencyMarketDataResp{
+			Price:             data[coinId][convCurr],
+			MarketCap:         data[coinId][convCurr+"_market_cap"],
+			Volume24:          data[coinId][convCurr+"_24h_vol"],
+			PriceChange24Perc: data[coinId][convCurr+"_24h_change"],
+		}
+	}
+	return res, nil
+}
+
+// GetCoinDescription returns coin descriptions (html) keyed by language code.
 func (p *Provider) GetCoinDescription(ctx context.Context, coinId string) (map[string]string, error) {
 	params := url.Values{
-		"localization":   {"false"},
+		"localization":   {"true"},
 		"tickers":        {"false"},
 		"market_data":    {"false"},
 		"community_data": {"false"},
@@ -197,44 +474,44 @@ func (p *Provider) GetCoinDescription(ctx context.Context, coinId string) (map[s
 	url := fmt.Sprintf("/coins/%s?", coinId) + params.Encode()
 	respBody, err := p.Do(ctx, "GET", url, nil)
 	if err != nil {
-		p.logger.Errorf("Can't GET coin info from coingecko. URL: %s: %v", url, err)
+		p.log(ctx).Errorf("Can't GET coin info from coingecko. URL: %s: %v", url, err)
 		return make(map[string]string), err
 	}
 
-	p.logger.Infof("Get success response")
+	p.log(ctx).Infof("Get success response")
 
 	var description CoinGeckoCoinData
 	if err = json.Unmarshal(respBody, &description); err != nil {
-		p.logger.Errorf("Can't unmarshal coingecko response GET body. %s %v", url, err)
+		p.log(ctx).Errorf("Can't unmarshal coingecko response GET body. %s %v", url, err)
 		return make(map[string]string), err
 	}
 	return description.Description, nil
 }
 
-func (p *Provider) GetMarketCapPercentage(ctx context.Context, coinShort string) (float32, error) {
+func (p *Provider) GetMarketCapPercentage(ctx context.Context, coinShort string) (decimal.Decimal, error) {
 	respBody, err := p.Do(ctx, "GET", "/global", nil)
 	if err != nil {
-		p.logger.Errorf("Can't GET global data from coingecko. %v", err)
-		return 0, err
+		p.log(ctx).Errorf("Can't GET global data from coingecko. %v", err)
+		return decimal.Decimal{}, err
 	}
 
-	p.logger.Infof("Get success response")
+	p.log(ctx).Infof("Get success response")
 	var globalData CoinGeckoGlobalCryptoData
 	err = json.Unmarshal(respBody, &globalData)
 	if err != nil {
-		p.logger.Errorf("Can't unmarshal coingecko response GET global data body. %v", err)
-		return 0, err
+		p.log(ctx).Errorf("Can't unmarshal coingecko response GET global data body. %v", err)
+		return decimal.Decimal{}, err
 	}
 
 	if res, found := globalData.MarketCapPercentage[coinShort]; found {
 		return res, nil
 	} else {
-		p.logger.Info("Market cap percentage in missing in global data for &s", coinShort)
-		return 0, nil
+		p.log(ctx).Warnf("Market cap percentage in missing in global data for %s", coinShort)
+		return decimal.Decimal{}, nil
 	}
 }
 
-func (p *Provider) Get24hVolume(ctx context.Context, coinId string, convCurr string) (float32, error) {
+func (p *Provider) Get24hVolume(ctx context.Context, coinId string, convCurr string) (decimal.Decimal, error) {
 	params := url.Values{
 		"ids":              {coinId},
 		"vs_currencies":    {convCurr},
@@ -243,52 +520,341 @@ func (p *Provider) Get24hVolume(ctx context.Context, coinId string, convCurr str
 	url := "/simple/price?" + params.Encode()
 	respBody, err := p.Do(ctx, "GET", url, nil)
 	if err != nil {
-		p.logger.Errorf("Can't GET simple price from coingecko. URL: %s: %v", url, err)
-		return 0, err
+		p.log(ctx).Errorf("Can't GET simple price from coingecko. URL: %s: %v", url, err)
+		return decimal.Decimal{}, err
 	}
 
-	p.logger.Infof("Get success response")
+	p.log(ctx).Infof("Get success response")
 
-	var data map[string]map[string]float32
+	var data map[string]map[string]decimal.Decimal
 	if err := json.Unmarshal(respBody, &data); err != nil {
-		p.logger.Errorf(
+		p.log(ctx).Errorf(
 			"Can't unmarshal coingecko response GET simple price body for coinId: %s: %v ", coinId, err,
 		)
-		return 0, err
+		return decimal.Decimal{}, err
 	}
 	h_volume_24 := data[coinId][fmt.Sprintf("%s_24h_vol", convCurr)]
 	return h_volume_24, nil
 }
 
+// supportedVsCurrenciesTTL is cache lifetime of /simple/supported_vs_currencies response.
+const supportedVsCurrenciesTTL = time.Hour
+
+// GetSupportedVsCurrencies returns currencies accepted by coingecko as vs_currency.
+// When coingecko is unavailable the last loaded list is returned, error is returned
+// only if the list has never been loaded.
+func (p *Provider) GetSupportedVsCurrencies(ctx context.Context) ([]string, error) {
+	p.log(ctx).Infof("Start GetSupportedVsCurrencies provider method...")
+
+	cacheKey := "supported_vs_currencies"
+	lastKey := "supported_vs_currencies_last"
+	if res, found := p.cacheGet(ctx, "supported_vs_currencies", cacheKey); found {
+		return res.([]string), nil
+	}
+
+	currencies, err := p.fetchSupportedVsCurrencies(ctx)
+	if err != nil {
+		if res, found := p.memCache.Get(lastKey); found {
+			p.log(ctx).Warnf("Using last loaded supported vs currencies: %v", err)
+			return res.([]string), nil
+		}
+		return nil, err
+	}
+	p.cacheSet(ctx, cacheKey, currencies, supportedVsCurrenciesTTL)
+	p.memCache.Set(lastKey, currencies, cache.NoExpiration)
+
+	return currencies, nil
+}
+
+func (p *Provider) fetchSupportedVsCurrencies(ctx context.Context) ([]string, error) {
+	respBody, err := p.Do(ctx, "GET", "/simple/supported_vs_currencies", nil)
+	if err != nil {
+		p.log(ctx).Errorf("Can't GET supported vs currencies from coingecko. %v", err)
+		return nil, err
+	}
+
+	p.log(ctx).Infof("Get success response")
+
+	var currencies []string
+	if err = json.Unmarshal(respBody, &currencies); err != nil {
+		p.log(ctx).Errorf("Can't unmarshal coingecko response GET supported vs currencies body. %v", err)
+		return nil, err
+	}
+	return currencies, nil
+}
+
+// AssetPlatformsTTL is cache lifetime of /asset_platforms response.
+const AssetPlatformsTTL = time.Hour
+
+// GetAssetPlatforms returns blockchain networks known to coingecko.
+func (p *Provider) GetAssetPlatforms(ctx context.Context) ([]CoinGeckoAssetPlatform, error) {
+	p.log(ctx).Infof("Start GetAssetPlatforms provider method...")
+
+	cacheKey := "asset_platforms"
+	if res, found := p.cacheGet(ctx, "asset_platforms", cacheKey); found {
+		return res.([]CoinGeckoAssetPlatform), nil
+	}
+
+	respBody, err := p.Do(ctx, "GET", "/asset_platforms", nil)
+	if err != nil {
+		p.log(ctx).Errorf("Can't GET asset platforms from coingecko. %v", err)
+		return nil, err
+	}
+
+	p.log(ctx).Infof("Get success response")
+
+	var platforms []CoinGeckoAssetPlatform
+	if err = json.Unmarshal(respBody, &platforms); err != nil {
+		p.log(ctx).Errorf("Can't unmarshal coingecko response GET asset platforms body. %v", err)
+		return nil, err
+	}
+	p.cacheSet(ctx, cacheKey, platforms, AssetPlatformsTTL)
+
+	return platforms, nil
+}
+
+// exchangeRatesTTL is cache lifetime of /exchange_rates response.
+const exchangeRatesTTL = time.Minute
+
+// crossRatePivot is the currency cross rates are computed through.
+// /exchange_rates values are expressed in it.
+const crossRatePivot = "btc"
+
+// GetExchangeRates returns BTC exchange rates for fiat, crypto and commodity units.
+func (p *Provider) GetExchangeRates(ctx context.Context) (map[string]CoinGeckoExchangeRate, error) {
+	p.log(ctx).Infof("Start GetExchangeRates provider method...")
+
+	cacheKey := "exchange_rates"
+	if res, found := p.cacheGet(ctx, "exchange_rates", cacheKey); found {
+		return res.(map[string]CoinGeckoExchangeRate), nil
+	}
+
+	respBody, err := p.Do(ctx, "GET", "/exchange_rates", nil)
+	if err != nil {
+		p.log(ctx).Errorf("Can't GET exchange rates from coingecko. %v", err)
+		return nil, err
+	}
+
+	p.log(ctx).Infof("Get success response")
+
+	var rates CoinGeckoExchangeRates
+	if err = json.Unmarshal(respBody, &rates); err != nil {
+		p.log(ctx).Errorf("Can't unmarshal coingecko response GET exchange rates body. %v", err)
+		return nil, err
+	}
+	p.cacheSet(ctx, cacheKey, rates.Rates, exchangeRatesTTL)
+
+	return rates.Rates, nil
+}
+
+// GetSimplePrice returns price of coinId in convCurr.
+func (p *Provider) GetSimplePrice(ctx context.Context, coinId string, convCurr string) (decimal.Decimal, error) {
+	params := url.Values{
+		"ids":           {coinId},
+		"vs_currencies": {convCurr},
+	}
+	url := "/simple/price?" + params.Encode()
+	respBody, err := p.Do(ctx, "GET", url, nil)
+	if err != nil {
+		p.log(ctx).Errorf("Can't GET simple price from coingecko. URL: %s: %v", url, err)
+		return decimal.Decimal{}, err
+	}
+
+	p.log(ctx).Infof("Get success response")
+
+	var data map[string]map[string]decimal.Decimal
+	if err := json.Unmarshal(respBody, &data); err != nil {
+		p.log(ctx).Errorf("Can't unmarshal coingecko response GET simple price body for coinId: %s: %v ", coinId, err)
+		return decimal.Decimal{}, err
+	}
+	price, found := data[coinId][convCurr]
+	if !found {
+		return decimal.Decimal{}, fmt.Errorf("no %s price for %s", convCurr, coinId)
+	}
+	return price, nil
+}
+
+// one is 1 for inverting rates.
+var one, _ = decimal.New("1")
+
+// GetCrossRate returns amount of to per 1 from, where from and to are coin symbols/names
+// or units listed by /exchange_rates (fiat currencies, commodities, major coins).
+// The rate is computed through BTC: units are converted with /exchange_rates,
+// other coins with /simple/price. Returned path lists the used rates.
+func (p *Provider) GetCrossRate(ctx context.Context, from string, to string) (decimal.Decimal, []models.ConvertStepResp, error) {
+	p.log(ctx).Infof("Start GetCrossRate provider method...")
+
+	fromStep, err := p.pivotRate(ctx, from)
+	if err != nil {
+		return decimal.Decimal{}, nil, err
+	}
+	toStep, err := p.pivotRate(ctx, to)
+	if err != nil {
+		return decimal.Decimal{}, nil, err
+	}
+	if toStep.Rate.IsZero() {
+		return decimal.Decimal{}, nil, fmt.Errorf("zero %s rate for %s", crossRatePivot, to)
+	}
+
+	path := make([]models.ConvertStepResp, 0, 2)
+	if !strings.EqualFold(from, crossRatePivot) {
+		path = append(path, fromStep)
+	}
+	if !strings.EqualFold(to, crossRatePivot) {
+		path = append(path, models.ConvertStepResp{
+			From:   crossRatePivot,
+			To:     to,
+			Rate:   one.Quo(toStep.Rate),
+			Source: toStep.Source,
+		})
+	}
+
+	return fromStep.Rate.Quo(toStep.Rate), path, nil
+}
+
+// pivotRate returns step converting 1 unit of currency into crossRatePivot.
+func (p *Provider) pivotRate(ctx context.Context, currency string) (models.ConvertStepResp, error) {
+	step := models.ConvertStepResp{From: currency, To: crossRatePivot}
+
+	rates, err := p.GetExchangeRates(ctx)
+	if err != nil {
+		return step, err
+	}
+	if rate, found := rates[strings.ToLower(currency)]; found && !rate.Value.IsZero() {
+		step.Rate = one.Quo(rate.Value)
+		step.Source = "/exchange_rates"
+		return step, nil
+	}
+
+	coinId, _, err := p.GetCoinIDName(ctx, currency)
+	if err != nil {
+		return step, err
+	}
+	step.Rate, err = p.GetSimplePrice(ctx, coinId, crossRatePivot)
+	if err != nil {
+		return step, err
+	}
+	step.Source = "/simple/price"
+	return step, nil
+}
+
+const (
+	// coinMissTTL is cache lifetime of symbol or name not found in coin index.
+	coinMissTTL = 5 * time.Minute
+	// coinIndexMinAge is age of coin index before which misses don't reload it.
+	coinIndexMinAge = 5 * time.Minute
+)
+
 func (p *Provider) GetCoinIDName(ctx context.Context, coinShort string) (string, string, error) {
-	p.logger.Infof("Starting GetCoinIDName provider method...")
+	p.log(ctx).Infof("Starting GetCoinIDName provider method...")
 
-	p.logger.Infof("Trying to get coinId and coinName from cache")
+	p.log(ctx).Infof("Trying to get coinId and coinName from cache")
 	cacheKey := fmt.Sprintf("%s_coin", coinShort)
+	if res, found := p.cacheGet(ctx, "coin_id", cacheKey); found {
+		coin := res.(*geckoCoin)
+		return coin.coinId, coin.coinName, nil
+	}
+	missKey := fmt.Sprintf("coin_miss_%s", coinShort)
+	if _, found := p.memCache.Get(missKey); found {
+		p.log(ctx).Errorf("Can't find coingecko coinId/Name for %s (cached miss)", coinShort)
+		return "", "", fmt.Errorf("GetCoinIDName error")
+	}
+
+	if err := p.reloadCoinIndex(ctx); err != nil {
+		p.log(ctx).Errorf("Can't GET coins list from coingecko for %s: %v", coinShort, err)
+		return "", "", err
+	}
 	if res, found := p.memCache.Get(cacheKey); found {
 		coin := res.(*geckoCoin)
 		return coin.coinId, coin.coinName, nil
 	}
 
-	p.logger.Infof("Getting from coingecko /coins/list endpoint")
+	p.memCache.Set(missKey, true, coinMissTTL)
+	p.log(ctx).Errorf("Can't find coingecko coinId/Name for %s", coinShort)
+	return "", "", fmt.Errorf("GetCoinIDName error")
+}
+
+// reloadCoinIndex loads coin index on miss unless it is younger than coinIndexMinAge,
+// so that requests of unknown coins don't download /coins/list each.
+func (p *Provider) reloadCoinIndex(ctx context.Context) error {
+	p.coinIndexMu.Lock()
+	defer p.coinIndexMu.Unlock()
+
+	loadedAt := atomic.LoadInt64(&p.coinIndexLoadedAt)
+	if loadedAt != 0 && time.Since(time.Unix(0, loadedAt)) < coinIndexMinAge {
+		return nil
+	}
+	return p.loadCoinIndex(ctx)
+}
+
+// LoadCoinIndex caches coin id and name of every coin from /coins/list by symbol and by name.
+// Coins earlier in the list win, so lookup result is the first coin matching symbol or name.
+func (p *Provider) LoadCoinIndex(ctx context.Context) error {
+	p.coinIndexMu.Lock()
+	defer p.coinIndexMu.Unlock()
+	return p.loadCoinIndex(ctx)
+}
+
+// loadCoinIndex loads coin index, must be called with p.coinIndexMu held.
+func (p *Provider) loadCoinIndex(ctx context.Context) error {
+	p.log(ctx).Infof("Getting from coingecko /coins/list endpoint")
 	coinGeckoClient := p.CreateCoinGeckoHttpClient()
+	if err := p.waitRateLimit(ctx); err != nil {
+		p.observeUpstream("/coins/list", time.Now(), errorClass(err))
+		return err
+	}
+	start := time.Now()
+	_, span := startUpstreamSpan(ctx, "/coins/list")
 	coins, err := coinGeckoClient.CoinsList()
+	endUpstreamSpan(ctx, span, err)
 	if err != nil {
-		p.logger.Errorf("Can't GET coins list from coingecko for %s: %v", coinShort, err)
-		return "", "", err
+		p.observeUpstream("/coins/list", start, errorClass(err))
+		return err
 	}
+	p.observeUpstream("/coins/list", start, classOK)
 
+	index := make(map[string]*geckoCoin, 2*len(*coins))
 	for _, coin := range *coins {
-		if coinShort == coin.Symbol || coinShort == coin.Name {
-			p.logger.Infof("Adding coin id:%s, name:%s into cache.", coin.ID, coin.Name)
-			p.memCache.Set(cacheKey, &geckoCoin{coinId: coin.ID, coinName: coin.Name}, cache.DefaultExpiration)
+		geckoCoin := &geckoCoin{coinId: coin.ID, coinName: coin.Name}
+		for _, key := range []string{fmt.Sprintf("%s_coin", coin.Symbol), fmt.Sprintf("%s_coin", coin.Name)} {
+			if _, found := index[key]; !found {
+				index[key] = geckoCoin
+			}
+		}
+	}
+	// reloaded index replaces coins changed since previous load
+	for key, geckoCoin := range index {
+		p.memCache.Set(key, geckoCoin, cache.NoExpiration)
+	}
+	p.log(ctx).Infof("Coin index loaded: %d coins", len(*coins))
+	atomic.StoreInt64(&p.coinIndexLoadedAt, time.Now().UnixNano())
+
+	return nil
+}
 
-			return coin.ID, coin.Name, nil
+// WatchCoinIndex loads coin index, retrying every retryInterval until it is loaded,
+// then reloads it every refreshInterval until ctx is done.
+func (p *Provider) WatchCoinIndex(ctx context.Context, retryInterval, refreshInterval time.Duration) {
+	for {
+		interval := refreshInterval
+		if err := p.LoadCoinIndex(ctx); err != nil {
+			interval = retryInterval
+			p.log(ctx).Errorf("Can't load coin index, retrying in %s: %v", interval, err)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
 		}
 	}
+}
 
-	p.logger.Errorf("Can't find coingecko coinId/Name for %s", coinShort)
-	return "", "", fmt.Errorf("GetCoinIDName error")
+// CoinIndexWarm reports whether coin index was loaded at least once.
+func (p *Provider) CoinIndexWarm() bool {
+	return atomic.LoadInt64(&p.coinIndexLoadedAt) != 0
 }
 
 func (p *Provider) CreateCoinGeckoHttpClient() *coingecko.Client {
@@ -305,6 +871,19 @@ func (p *Provider) Do(
 	reqURL string,
 	reqBody io.Reader,
 ) ([]byte, error) {
+	endpoint := urlTemplate(reqURL)
+	if err := p.waitRateLimit(ctx); err != nil {
+		p.observeUpstream(endpoint, time.Now(), errorClass(err))
+		return []byte{}, err
+	}
+	start := time.Now()
+	class := classOK
+	defer func() { p.observeUpstream(endpoint, start, class) }()
+
+	ctx, span := startUpstreamSpan(ctx, endpoint)
+	var err error
+	defer func() { endUpstreamSpan(ctx, span, err) }()
+
 	httpClient := http.Client{
 		Timeout: time.Duration(1) * time.Second,
 	}
@@ -313,30 +892,40 @@ func (p *Provider) Do(
 		return []byte{}, err
 	}
 	req.Header.Add("Accept", `application/json`)
+	// span is local, trace context is not propagated to third party API
+	span.SetAttributes(semconv.HTTPClientAttributesFromHTTPRequest(req)...)
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		p.logger.Errorf("can't send coingecko request: %v", err)
+		class = errorClass(err)
+		p.log(ctx).Errorf("can't send coingecko request: %v", err)
 		return []byte{}, err
 	}
 	defer func() { _ = resp.Body.Close() }()
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)...)
+	if resp.StatusCode == http.StatusOK {
+		cachecontrol.Observe(ctx, cachecontrol.MaxAge(resp.Header))
+	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		p.logger.Errorf("can't read coingecko response: %v", err)
+		class = classRead
+		p.log(ctx).Errorf("can't read coingecko response: %v", err)
 		return []byte{}, err
 	}
 
 	if resp.StatusCode != 200 {
-		p.logger.Errorf("Wrong status code! %d", resp.StatusCode)
+		class = statusClass(resp.StatusCode)
+		p.log(ctx).Errorf("Wrong status code! %d", resp.StatusCode)
 		var prettyJSON bytes.Buffer
 		err = json.Indent(&prettyJSON, body, "", "\t")
 		if err != nil {
-			p.logger.Errorf("JSON parse error: %v", err)
+			p.log(ctx).Errorf("JSON parse error: %v", err)
 			return []byte{}, err
 		}
-		p.logger.Errorf("Error json res: %s", prettyJSON.String())
-		return []byte{}, fmt.Errorf("wrong status code resp")
+		p.log(ctx).Errorf("Error json res: %s", prettyJSON.String())
+		err = fmt.Errorf("wrong status code resp")
+		return []byte{}, err
 	}
 
 	return body, nil
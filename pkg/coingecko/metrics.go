@@ -0,0 +1,159 @@
+package coingeckoprovider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"external-metrics/pkg/tools/cachecontrol"
+	"external-metrics/pkg/tools/prom"
+)
+
+const upstreamName = "coingecko"
+
+// upstream call error classes
+const (
+	classOK         = "ok"
+	classTimeout    = "timeout"
+	classCanceled   = "canceled"
+	classConnection = "connection"
+	classRead       = "read"
+	classRateLimit  = "http_429"
+	classClient     = "http_4xx"
+	classServer     = "http_5xx"
+	classStatus     = "http_other"
+)
+
+var (
+	upstreamRequests = prom.NewCounterVec(
+		"upstream_requests_total",
+		"Upstream API calls by endpoint template and result class.",
+		"upstream", "endpoint", "class",
+	)
+	upstreamDuration = prom.NewHistogramVec(
+		"upstream_request_duration_seconds",
+		"Upstream API calls latency by endpoint template.",
+		prom.DefBuckets,
+		"upstream", "endpoint",
+	)
+	upstreamRateLimitWait = prom.NewHistogramVec(
+		"upstream_ratelimit_wait_seconds",
+		"Time upstream calls waited for outbound rate limiter.",
+		prom.DefBuckets,
+		"upstream",
+	)
+	upstreamCacheRequests = prom.NewCounterVec(
+		"upstream_cache_requests_total",
+		"Provider cache lookups by cache and result (hit or miss).",
+		"upstream", "cache", "result",
+	)
+
+	cacheHits, cacheLookups int64
+	_                       = prom.NewGaugeFunc(
+		"upstream_cache_hit_ratio",
+		"Share of coingecko provider cache lookups served from cache.",
+		func() float64 {
+			lookups := atomic.LoadInt64(&cacheLookups)
+			if lookups == 0 {
+				return 0
+			}
+			return float64(atomic.LoadInt64(&cacheHits)) / float64(lookups)
+		},
+	)
+)
+
+// observeUpstream records upstream call result.
+func (p *Provider) observeUpstream(endpoint string, start time.Time, class string) {
+	upstreamRequests.Inc(upstreamName, endpoint, class)
+	upstreamDuration.Observe(time.Since(start).Seconds(), upstreamName, endpoint)
+	p.recentCalls.record(start, class)
+}
+
+// errorClass returns class of failed request error.
+func errorClass(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return classCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return classTimeout
+	default:
+		return classConnection
+	}
+}
+
+// statusClass returns class of response status code.
+func statusClass(status int) string {
+	switch {
+	case status == 200:
+		return classOK
+	case status == 429:
+		return classRateLimit
+	case status >= 400 && status < 500:
+		return classClient
+	case status >= 500:
+		return classServer
+	default:
+		return classStatus
+	}
+}
+
+// cacheGet looks key up in provider cache and records hit or miss of cache.
+// Remaining TTL of found value limits freshness of response of ctx.
+func (p *Provider) cacheGet(ctx context.Context, cache string, key string) (interface{}, bool) {
+	res, expiration, found := p.memCache.GetWithExpiration(key)
+	traceCacheLookup(ctx, cache, found)
+	if found {
+		observeExpiration(ctx, expiration)
+	}
+
+	result := "miss"
+	if found {
+		result = "hit"
+		atomic.AddInt64(&cacheHits, 1)
+	}
+	atomic.AddInt64(&cacheLookups, 1)
+	upstreamCacheRequests.Inc(upstreamName, cache, result)
+
+	return res, found
+}
+
+// cacheSet stores value in provider cache, its TTL limits freshness of response of ctx.
+// ttl of cache.NoExpiration or cache.DefaultExpiration means value never changes.
+func (p *Provider) cacheSet(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	p.memCache.Set(key, value, ttl)
+	if ttl <= 0 {
+		cachecontrol.ObserveImmutable(ctx)
+		return
+	}
+	cachecontrol.Observe(ctx, ttl)
+}
+
+func observeExpiration(ctx context.Context, expiration time.Time) {
+	if expiration.IsZero() {
+		cachecontrol.ObserveImmutable(ctx)
+		return
+	}
+	cachecontrol.Observe(ctx, time.Until(expiration))
+}
+
+// urlTemplate replaces ids, platforms and addresses in request URL with placeholders
+// and drops query, so that it can be used as low-cardinality label.
+func urlTemplate(reqURL string) string {
+	path := strings.SplitN(reqURL, "?", 2)[0]
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case len(segments) >= 4 && segments[0] == "coins" && segments[2] == "contract":
+		segments[1], segments[3] = "{platform}", "{address}"
+	case len(segments) >= 2 && segments[0] == "coins" && segments[1] != "list" && segments[1] != "markets":
+		segments[1] = "{id}"
+	case len(segments) >= 3 && segments[0] == "simple" && segments[1] == "token_price":
+		segments[2] = "{platform}"
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
@@ -1,7 +1,9 @@
 package coingeckoprovider
 
+import "external-metrics/pkg/tools/decimal"
+
 type CoinGeckoMarketCapPercentage struct {
-	MarketCapPercentage map[string]float32 `json:"market_cap_percentage"`
+	MarketCapPercentage map[string]decimal.Decimal `json:"market_cap_percentage"`
 }
 
 type CoinGeckoGlobalCryptoData struct {
@@ -18,11 +20,65 @@ type CoinGeckoIcons struct {
 	Large string `json:"large"`
 }
 
+type CoinGeckoDetailPlatform struct {
+	DecimalPlace    *int   `json:"decimal_place"`
+	ContractAddress string `json:"contract_address"`
+}
+
+// /coins/{network}/contract/{address}
 type CoinGeckoDefiCoinInfo struct {
-	Name       string         `json:"name"`
-	Symbol     string         `json:"symbol"`
-	Icons      CoinGeckoIcons `json:"image"`
+	ID              string                             `json:"id"`
+	Name            string                             `json:"name"`
+	Symbol          string                             `json:"symbol"`
+	Icons           CoinGeckoIcons                     `json:"image"`
+	Platforms       map[string]string                  `json:"platforms"`
+	DetailPlatforms map[string]CoinGeckoDetailPlatform `json:"detail_platforms"`
+	MarketData      struct {
+		CurrentPrice                        map[string]decimal.Decimal `json:"current_price"`
+		MarketCap                           map[string]decimal.Decimal `json:"market_cap"`
+		TotalVolume                         map[string]decimal.Decimal `json:"total_volume"`
+		PriceChangePercentage1hInCurrency   map[string]decimal.Decimal `json:"price_change_percentage_1h_in_currency"`
+		PriceChangePercentage24hInCurrency  map[string]decimal.Decimal `json:"price_change_percentage_24h_in_currency"`
+		PriceChangePercentage7dInCurrency   map[string]decimal.Decimal `json:"price_change_percentage_7d_in_currency"`
+		PriceChangePercentage14dInCurrency  map[string]decimal.Decimal `json:"price_change_percentage_14d_in_currency"`
+		PriceChangePercentage30dInCurrency  map[string]decimal.Decimal `json:"price_change_percentage_30d_in_currency"`
+		PriceChangePercentage200dInCurrency map[string]decimal.Decimal `json:"price_change_percentage_200d_in_currency"`
+		PriceChangePercentage1yInCurrency   map[string]decimal.Decimal `json:"price_change_percentage_1y_in_currency"`
+		CirculatingSupply                   decimal.Decimal            `json:"circulating_supply"`
+		TotalSupply                         decimal.Decimal            `json:"total_supply"`
+		MaxSupply                           decimal.Decimal            `json:"max_supply"`
+	} `json:"market_data"`
+}
+
+// /coins/{id}/history
+type CoinGeckoCoinHistory struct {
+	ID         string `json:"id"`
+	Symbol     string `json:"symbol"`
+	Name       string `json:"name"`
 	MarketData struct {
-		CurrentPrice map[string]float32 `json:"current_price"`
+		CurrentPrice map[string]decimal.Decimal `json:"current_price"`
+		MarketCap    map[string]decimal.Decimal `json:"market_cap"`
+		TotalVolume  map[string]decimal.Decimal `json:"total_volume"`
 	} `json:"market_data"`
 }
+
+// /exchange_rates
+// Value is amount of the unit per 1 BTC
+type CoinGeckoExchangeRate struct {
+	Name  string          `json:"name"`
+	Unit  string          `json:"unit"`
+	Value decimal.Decimal `json:"value"`
+	Type  string          `json:"type"` // fiat, crypto or commodity
+}
+
+type CoinGeckoExchangeRates struct {
+	Rates map[string]CoinGeckoExchangeRate `json:"rates"`
+}
+
+// /asset_platforms
+type CoinGeckoAssetPlatform struct {
+	ID              string `json:"id"`
+	ChainIdentifier *int64 `json:"chain_identifier"`
+	Name            string `json:"name"`
+	ShortName       string `json:"shortname"`
+}
@@ -0,0 +1,109 @@
+package coingeckoprovider
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"external-metrics/metrics/models"
+)
+
+// statusWindow is number of recent upstream calls summarised by Status.
+const statusWindow = 100
+
+// circuitStateNone is circuit state of upstream without circuit breaker.
+const circuitStateNone = "none"
+
+type upstreamCall struct {
+	at       time.Time
+	duration time.Duration
+	class    string
+}
+
+// callLog is ring buffer of last statusWindow upstream calls.
+type callLog struct {
+	mu    sync.Mutex
+	calls []upstreamCall
+	next  int
+}
+
+// record stores upstream call result.
+func (l *callLog) record(start time.Time, class string) {
+	call := upstreamCall{at: start, duration: time.Since(start), class: class}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.calls) < statusWindow {
+		l.calls = append(l.calls, call)
+		return
+	}
+	l.calls[l.next] = call
+	l.next = (l.next + 1) % statusWindow
+}
+
+// snapshot returns copy of recorded calls.
+func (l *callLog) snapshot() []upstreamCall {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]upstreamCall(nil), l.calls...)
+}
+
+// Status summarises last statusWindow coingecko calls: success rate, latency and last success and error.
+// Calls to coingecko don't go through circuit breaker, so circuit state is always none.
+func (p *Provider) Status() models.UpstreamStatusResp {
+	calls := p.recentCalls.snapshot()
+
+	status := models.UpstreamStatusResp{
+		Name:          upstreamName,
+		Window:        statusWindow,
+		Calls:         len(calls),
+		CircuitState:  circuitStateNone,
+		CoinIndexWarm: p.CoinIndexWarm(),
+	}
+	if len(calls) == 0 {
+		return status
+	}
+
+	var (
+		succeeded int
+		total     time.Duration
+	)
+	durations := make([]time.Duration, 0, len(calls))
+	for _, call := range calls {
+		total += call.duration
+		durations = append(durations, call.duration)
+		if call.class == classOK {
+			succeeded++
+			if status.LastSuccess == nil || call.at.After(time.Unix(*status.LastSuccess, 0)) {
+				at := call.at.Unix()
+				status.LastSuccess = &at
+			}
+			continue
+		}
+		if status.LastError == nil || call.at.After(time.Unix(*status.LastError, 0)) {
+			at := call.at.Unix()
+			status.LastError = &at
+			status.LastErrorClass = call.class
+		}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	status.SuccessRate = float64(succeeded) / float64(len(calls))
+	status.AvgLatencyMs = total.Milliseconds() / int64(len(calls))
+	status.P95LatencyMs = durations[(len(durations)*95-1)/100].Milliseconds()
+
+	return status
+}
+
+// CheckCache verifies provider cache accepts and returns values.
+func (p *Provider) CheckCache() error {
+	const probeKey = "readiness_probe"
+
+	probe := time.Now().UnixNano()
+	p.memCache.Set(probeKey, probe, time.Minute)
+	if res, found := p.memCache.Get(probeKey); !found || res.(int64) != probe {
+		return fmt.Errorf("cache probe value not found")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package coingeckoprovider
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+)
+
+const tracerName = "external-metrics/pkg/coingecko"
+
+var (
+	attrUpstream    = label.Key("upstream")
+	attrURLTemplate = label.Key("http.url_template")
+	attrCache       = label.Key("cache")
+	attrCacheHit    = label.Key("cache.hit")
+)
+
+// startUpstreamSpan starts client span of upstream call to endpoint template.
+func startUpstreamSpan(ctx context.Context, endpoint string) (context.Context, trace.Span) {
+	return global.Tracer(tracerName).Start(
+		ctx,
+		upstreamName+" "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrUpstream.String(upstreamName), attrURLTemplate.String(endpoint)),
+	)
+}
+
+// endUpstreamSpan records error of upstream call, if any, and ends span.
+func endUpstreamSpan(ctx context.Context, span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(ctx, err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceCacheLookup adds cache lookup event to current span.
+func traceCacheLookup(ctx context.Context, cache string, found bool) {
+	trace.SpanFromContext(ctx).AddEvent(ctx, "cache lookup", attrCache.String(cache), attrCacheHit.Bool(found))
+}
@@ -0,0 +1,74 @@
+package httperror
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"external-metrics/pkg/tools/cachecontrol"
+
+	"github.com/gin-gonic/gin"
+)
+
+const jsonContentType = "application/json; charset=utf-8"
+
+// CacheableJSON writes obj as JSON response with caching headers, see writeJSON.
+func CacheableJSON(c *gin.Context, status int, obj interface{}) {
+	writeJSON(c, status, obj, obj)
+}
+
+// writeJSON writes obj as JSON. Successful GET responses get weak ETag of etagObj JSON,
+// Cache-Control by freshness of data collected in request context and 304 for matching
+// If-None-Match. Other responses are not stored by caches.
+// etagObj is obj without per-request fields, so that equal data has equal ETag.
+// obj which can't be encoded is replaced by internal server error.
+func writeJSON(c *gin.Context, status int, obj interface{}, etagObj interface{}) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		Abort(c, http.StatusInternalServerError, ErrorView{Message: "internal server error"})
+		return
+	}
+
+	method := c.Request.Method
+	if status != http.StatusOK || (method != http.MethodGet && method != http.MethodHead) {
+		c.Header("Cache-Control", "no-store")
+		c.Data(status, jsonContentType, body)
+		return
+	}
+
+	etagBody, err := json.Marshal(etagObj)
+	if err != nil {
+		Abort(c, http.StatusInternalServerError, ErrorView{Message: "internal server error"})
+		return
+	}
+	sum := sha256.Sum256(etagBody)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", cachecontrol.FromContext(c.Request.Context()).Header())
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	c.Data(status, jsonContentType, body)
+}
+
+// etagMatches is weak comparison of etag with If-None-Match list.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
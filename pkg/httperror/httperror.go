@@ -1,16 +1,21 @@
 package httperror
 
 import (
+	"errors"
+	"external-metrics/pkg/requestid"
+	"external-metrics/pkg/tools/decimal"
 	"external-metrics/pkg/tools/logging"
 	"fmt"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type BaseResponse struct {
-	Result bool        `json:"result"`
-	Errors []error     `json:"errors,omitempty"`
-	Data   interface{} `json:"data,omitempty"`
+	Result    bool        `json:"result"`
+	Errors    []error     `json:"errors,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
 func Response(errors []error, data interface{}) BaseResponse {
@@ -24,22 +29,70 @@ func Response(errors []error, data interface{}) BaseResponse {
 // ErrorView.
 type ErrorView struct {
 	Code    int    `json:"-"`
+	Field   string `json:"field,omitempty"`
 	Message string `json:"message"`
 }
 
+// FieldError returns ErrorView describing invalid request field.
+func FieldError(field string, message string) ErrorView {
+	return ErrorView{Code: http.StatusBadRequest, Field: field, Message: message}
+}
+
+// Status returns Code of ErrorView in err chain or fallback.
+func Status(err error, fallback int) int {
+	var view ErrorView
+	if errors.As(err, &view) && view.Code != 0 {
+		return view.Code
+	}
+	return fallback
+}
+
 // ErrorView.
 func (err ErrorView) Error() string {
+	if err.Field != "" {
+		return fmt.Sprintf("%d: %s: %s", err.Code, err.Field, err.Message)
+	}
 	return fmt.Sprintf("%d: %s", err.Code, err.Message)
 }
 
+// Abort stops handlers chain responding with err in BaseResponse envelope.
+func Abort(c *gin.Context, status int, err error) {
+	var view ErrorView
+	if !errors.As(err, &view) {
+		view = ErrorView{Message: err.Error()}
+	}
+	response := Response([]error{view}, nil)
+	response.RequestID = requestid.FromContext(c.Request.Context())
+	c.Header("Cache-Control", "no-store")
+	c.AbortWithStatusJSON(status, response)
+}
+
+// numbersQueryArg switches response numbers encoding: numbers=string encodes them as strings.
+const numbersQueryArg = "numbers"
+
 func ErrorWrapper(logger *logging.Logger, handler func(c *gin.Context) (resp interface{}, status int, err error)) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		errors := make([]error, 0)
+		logger := logging.FromContext(c.Request.Context(), logger)
+		errs := make([]error, 0)
 		resp, status, err := handler(c)
 		if err != nil {
 			logger.Error(err)
-			errors = append(errors, ErrorView{Message: err.Error()})
+			var view ErrorView
+			if !errors.As(err, &view) {
+				view = ErrorView{Message: err.Error()}
+			}
+			errs = append(errs, view)
+		}
+		if resp != nil && c.Query(numbersQueryArg) == "string" {
+			if resp, err = decimal.NumbersToStrings(resp); err != nil {
+				logger.Errorf("Can't encode response numbers as strings: %v", err)
+				status = http.StatusInternalServerError
+				errs = append(errs, ErrorView{Message: "internal server error"})
+			}
 		}
-		c.JSON(status, Response(errors, resp))
+		response := Response(errs, resp)
+		etagResponse := response
+		response.RequestID = requestid.FromContext(c.Request.Context())
+		writeJSON(c, status, response, etagResponse)
 	}
 }
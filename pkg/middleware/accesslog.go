@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// accessTimeFormat is time format of common log format.
+const accessTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLog writes access log line of every request with request-scoped logger,
+// so that the line has request id. 5xx are logged as errors, 4xx as warnings.
+func AccessLog(logger *logging.Logger) gin.HandlerFunc {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return func(c *gin.Context) {
+		// handlers may change c.Request.URL
+		path := c.Request.URL.Path
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start).Milliseconds()
+
+		status := c.Writer.Status()
+		dataLength := c.Writer.Size()
+		if dataLength < 0 {
+			dataLength = 0
+		}
+
+		entry := logging.FromContext(c.Request.Context(), logger).WithFields(map[string]interface{}{
+			"hostname":   hostname,
+			"statusCode": status,
+			"latency":    latency,
+			"clientIP":   c.ClientIP(),
+			"method":     c.Request.Method,
+			"path":       path,
+			"referer":    c.Request.Referer(),
+			"dataLength": dataLength,
+			"userAgent":  c.Request.UserAgent(),
+		})
+
+		if len(c.Errors) > 0 {
+			entry.Error(c.Errors.ByType(gin.ErrorTypePrivate).String())
+			return
+		}
+		msg := fmt.Sprintf(
+			"%s - %s [%s] \"%s %s\" %d %d \"%s\" \"%s\" (%dms)",
+			c.ClientIP(), hostname, time.Now().Format(accessTimeFormat), c.Request.Method, path,
+			status, dataLength, c.Request.Referer(), c.Request.UserAgent(), latency,
+		)
+		switch {
+		case status >= http.StatusInternalServerError:
+			entry.Error(msg)
+		case status >= http.StatusBadRequest:
+			entry.Warn(msg)
+		default:
+			entry.Info(msg)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"external-metrics/pkg/apikeys"
+	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries client API key. Query arg is not accepted, as URLs get into logs and traces.
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyCtxKey is gin context key of apikeys.Key of request.
+const apiKeyCtxKey = "api_key"
+
+// APIKey authenticates request by API key and applies per-key rate limit and daily quota.
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset describe the tightest of them.
+func APIKey(store *apikeys.Store, logger *logging.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := logging.FromContext(c.Request.Context(), logger)
+
+		apiKey := c.GetHeader(APIKeyHeader)
+		if apiKey == "" {
+			httperror.Abort(c, http.StatusUnauthorized, fmt.Errorf("api key is required"))
+			return
+		}
+
+		now := time.Now()
+		decision := store.Allow(apiKey, now)
+		if decision.Key == nil {
+			logger.Warnf("Rejected request with invalid api key")
+			httperror.Abort(c, http.StatusUnauthorized, errors.New(decision.Reason))
+			return
+		}
+
+		if decision.Limit > 0 {
+			c.Header("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+			c.Header("X-RateLimit-Reset", strconv.FormatInt(decision.Reset.Unix(), 10))
+		}
+		if !decision.Allowed {
+			logger.Warnf("Rejected request of %s: %s", decision.Key.Name, decision.Reason)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(decision.RetryAfter.Seconds()))))
+			httperror.Abort(c, http.StatusTooManyRequests, errors.New(decision.Reason))
+			return
+		}
+
+		// responses depend on key, shared caches must not serve them to other keys
+		c.Writer.Header().Add("Vary", APIKeyHeader)
+		c.Set(apiKeyCtxKey, decision.Key)
+		c.Request = c.Request.WithContext(logging.NewContext(
+			c.Request.Context(), logger.WithField("client_name", decision.Key.Name),
+		))
+		c.Next()
+	}
+}
+
+// RequireAdmin allows only requests authenticated by admin API key, must follow APIKey.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key, ok := c.Value(apiKeyCtxKey).(*apikeys.Key); !ok || !key.Admin {
+			httperror.Abort(c, http.StatusForbidden, fmt.Errorf("admin api key is required"))
+			return
+		}
+		c.Next()
+	}
+}
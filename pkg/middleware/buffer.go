@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"bytes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bufferedWriter holds response body until middleware flushes it, so that headers
+// depending on the whole body can still be set.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// bufferResponse replaces writer of c with bufferedWriter.
+func bufferResponse(c *gin.Context) *bufferedWriter {
+	w := &bufferedWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+	return w
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// Written reports whether body or headers were written, buffered body counts.
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// Flush is no-op, body is written by flush.
+func (w *bufferedWriter) Flush() {}
+
+// flush restores original writer of c and writes body to it.
+func (w *bufferedWriter) flush(c *gin.Context, body []byte) {
+	c.Writer = w.ResponseWriter
+	if len(body) == 0 {
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	_, _ = c.Writer.Write(body)
+}
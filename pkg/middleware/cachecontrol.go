@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"external-metrics/pkg/tools/cachecontrol"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheControl puts into request context Freshness, which provider fills with TTLs of data
+// used for response and httperror turns into Cache-Control header.
+func CacheControl() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, _ := cachecontrol.NewContext(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Compression gzips responses of at least minSize bytes for clients accepting gzip.
+// level is gzip compression level, 0 or invalid level means gzip.DefaultCompression.
+func Compression(minSize int, level int) gin.HandlerFunc {
+	if level == 0 || level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		level = gzip.DefaultCompression
+	}
+	writers := sync.Pool{New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(nil, level)
+		return w
+	}}
+
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		if c.Request.Method == http.MethodHead || !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		w := bufferResponse(c)
+		c.Next()
+
+		body := w.body.Bytes()
+		if len(body) < minSize || w.Header().Get("Content-Encoding") != "" || w.ResponseWriter.Written() {
+			w.flush(c, body)
+			return
+		}
+
+		var compressed bytes.Buffer
+		gz := writers.Get().(*gzip.Writer)
+		defer writers.Put(gz)
+		gz.Reset(&compressed)
+		if _, err := gz.Write(body); err != nil {
+			w.flush(c, body)
+			return
+		}
+		if err := gz.Close(); err != nil {
+			w.flush(c, body)
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Length", strconv.Itoa(compressed.Len()))
+		w.flush(c, compressed.Bytes())
+	}
+}
+
+// acceptsGzip reports whether Accept-Encoding allows gzip.
+func acceptsGzip(acceptEncoding string) bool {
+	for _, item := range strings.Split(acceptEncoding, ",") {
+		parts := strings.Split(item, ";")
+		coding := strings.ToLower(strings.TrimSpace(parts[0]))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if q := strings.TrimPrefix(param, "q="); q != param {
+				if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"external-metrics/pkg/tools/prom"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	httpRequests = prom.NewCounterVec(
+		"http_requests_total",
+		"Proxy HTTP requests by route, method and status.",
+		"route", "method", "status",
+	)
+	httpDuration = prom.NewHistogramVec(
+		"http_request_duration_seconds",
+		"Proxy HTTP requests latency by route, method and status.",
+		prom.DefBuckets,
+		"route", "method", "status",
+	)
+)
+
+// Metrics records count and latency of requests per route template and status.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		method := methodLabel(c.Request.Method)
+
+		httpRequests.Inc(route, method, status)
+		httpDuration.Observe(time.Since(start).Seconds(), route, method, status)
+	}
+}
+
+// methodLabel returns method of served methods or "other", so that arbitrary methods don't add series.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodOptions, http.MethodHead:
+		return method
+	default:
+		return "other"
+	}
+}
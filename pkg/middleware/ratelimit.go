@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"external-metrics/pkg/httperror"
+	"external-metrics/pkg/ratelimit"
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IPRateLimit limits requests per client IP. Routes of routeLimiters, keyed by route template,
+// are limited by their own limiter instead of defaultLimiter, nil limiter doesn't limit.
+// Client IP is taken from X-Forwarded-For only behind trusted proxies of the engine.
+func IPRateLimit(
+	defaultLimiter *ratelimit.Limiter,
+	routeLimiters map[string]*ratelimit.Limiter,
+	logger *logging.Logger,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter, found := routeLimiters[c.FullPath()]
+		if !found {
+			limiter = defaultLimiter
+		}
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		clientIP := c.ClientIP()
+		allowed, retryAfter := limiter.Allow(clientIP, time.Now())
+		if !allowed {
+			logging.FromContext(c.Request.Context(), logger).Warnf("Rate limit exceeded for %s", clientIP)
+			retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfterSeconds))
+			httperror.Abort(c, http.StatusTooManyRequests, fmt.Errorf("too many requests, retry after %d s", retryAfterSeconds))
+			return
+		}
+		c.Next()
+	}
+}
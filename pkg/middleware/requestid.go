@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"external-metrics/pkg/requestid"
+	"external-metrics/pkg/tools/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID accepts X-Request-ID of request or generates new one, returns it in response header
+// and puts it with logger of request id, route and client into request context.
+func RequestID(logger *logging.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		c.Header(requestid.Header, id)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		requestLogger := logger.WithFields(map[string]interface{}{
+			"request_id": id,
+			"route":      route,
+			"method":     c.Request.Method,
+			"client":     c.ClientIP(),
+		})
+
+		ctx := requestid.NewContext(c.Request.Context(), id)
+		ctx = logging.NewContext(ctx, requestLogger)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/semconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tracing starts server span per request continuing W3C trace context of incoming headers.
+// The span is put into request context, so handlers must pass c.Request.Context() downstream.
+func Tracing(serviceName string) gin.HandlerFunc {
+	tracer := global.Tracer("external-metrics/pkg/middleware")
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := global.TextMapPropagator().Extract(c.Request.Context(), c.Request.Header)
+		ctx, span := tracer.Start(
+			ctx,
+			c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(serviceName, route, c.Request)...),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(status))
+	}
+}
@@ -0,0 +1,271 @@
+package networks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"external-metrics/metrics/models"
+	coingeckoprovider "external-metrics/pkg/coingecko"
+	"external-metrics/pkg/tools/logging"
+)
+
+// Coingecko platform ids of the networks with explorers in config.
+const (
+	PlatformEthereum = "ethereum"
+	PlatformBSC      = "binance-smart-chain"
+)
+
+// knownNetwork is a network the registry resolves even when coingecko /asset_platforms is unavailable.
+type knownNetwork struct {
+	platform string
+	chainID  int64
+	name     string
+	aliases  []string
+	evm      bool // contract addresses are EVM addresses
+}
+
+var knownNetworks = []knownNetwork{
+	{platform: PlatformEthereum, chainID: 1, name: "Ethereum", aliases: []string{"eth", "mainnet"}, evm: true},
+	{platform: PlatformBSC, chainID: 56, name: "BNB Smart Chain", aliases: []string{"bsc", "bnb"}, evm: true},
+	{platform: "polygon-pos", chainID: 137, name: "Polygon POS", aliases: []string{"polygon", "matic"}, evm: true},
+	{platform: "arbitrum-one", chainID: 42161, name: "Arbitrum One", aliases: []string{"arbitrum", "arb"}, evm: true},
+	{platform: "optimistic-ethereum", chainID: 10, name: "Optimism", aliases: []string{"optimism", "op"}, evm: true},
+	{platform: "avalanche", chainID: 43114, name: "Avalanche", aliases: []string{"avax"}, evm: true},
+	{platform: "fantom", chainID: 250, name: "Fantom", aliases: []string{"ftm"}, evm: true},
+	{platform: "xdai", chainID: 100, name: "Gnosis", aliases: []string{"gnosis"}, evm: true},
+	{platform: "base", chainID: 8453, name: "Base", evm: true},
+	{platform: "cronos", chainID: 25, name: "Cronos", aliases: []string{"cro"}, evm: true},
+	{platform: "celo", chainID: 42220, name: "Celo", evm: true},
+	{platform: "moonbeam", chainID: 1284, name: "Moonbeam", aliases: []string{"glmr"}, evm: true},
+	{platform: "moonriver", chainID: 1285, name: "Moonriver", aliases: []string{"movr"}, evm: true},
+	{platform: "harmony-shard-0", chainID: 1666600000, name: "Harmony", aliases: []string{"harmony"}, evm: true},
+	{platform: "metis-andromeda", chainID: 1088, name: "Metis Andromeda", aliases: []string{"metis"}, evm: true},
+	{platform: "aurora", chainID: 1313161554, name: "Aurora", evm: true},
+	{platform: "kava", chainID: 2222, name: "Kava", evm: true},
+	{platform: "linea", chainID: 59144, name: "Linea", evm: true},
+	{platform: "zksync", chainID: 324, name: "zkSync", evm: true},
+	{platform: "polygon-zkevm", chainID: 1101, name: "Polygon zkEVM", evm: true},
+	{platform: "mantle", chainID: 5000, name: "Mantle", evm: true},
+	{platform: "scroll", chainID: 534352, name: "Scroll", evm: true},
+}
+
+const (
+	// indexRetryTTL is lifetime of index built without coingecko platforms, after which they are requested again.
+	indexRetryTTL = time.Minute
+	// indexRefreshTimeout bounds platforms request of index refresh.
+	indexRefreshTimeout = 10 * time.Second
+)
+
+// Registry resolves network identifiers (coingecko platform ids, EVM chain ids and aliases)
+// into coingecko asset platforms and configured explorers.
+type Registry struct {
+	coingeckoProvider *coingeckoprovider.Provider
+	explorers         map[string]models.ExplorerResp
+	logger            *logging.Logger
+
+	mu         sync.Mutex
+	index      *index
+	expires    time.Time
+	refreshing chan struct{} // closed when running refresh completes, nil if none
+}
+
+// index is networks list with networks by alias, rebuilt when platforms cache expires.
+type index struct {
+	networks []models.NetworkResp
+	byAlias  map[string]*models.NetworkResp
+}
+
+// NewRegistry returns network registry. explorers are keyed by coingecko platform id.
+func NewRegistry(
+	coingeckoProvider *coingeckoprovider.Provider,
+	explorers map[string]models.ExplorerResp,
+	logger *logging.Logger,
+) *Registry {
+	return &Registry{
+		coingeckoProvider: coingeckoProvider,
+		explorers:         explorers,
+		logger:            logger,
+	}
+}
+
+// Networks returns all known networks sorted by platform id.
+func (r *Registry) Networks(ctx context.Context) []models.NetworkResp {
+	return append([]models.NetworkResp(nil), r.getIndex(ctx).networks...)
+}
+
+// Resolve returns network matching identifier: coingecko platform id, EVM chain id or alias.
+func (r *Registry) Resolve(ctx context.Context, identifier string) (*models.NetworkResp, error) {
+	identifier = strings.ToLower(strings.TrimSpace(identifier))
+
+	if network, found := r.getIndex(ctx).byAlias[identifier]; found {
+		network := *network
+		return &network, nil
+	}
+
+	return nil, fmt.Errorf("unknown network %q", identifier)
+}
+
+// getIndex returns cached index. Expired index is refreshed in background by one
+// refresh at a time, meanwhile callers get the previous index. Only the first callers,
+// before any index is built, wait for refresh or their ctx.
+func (r *Registry) getIndex(ctx context.Context) *index {
+	r.mu.Lock()
+	if r.index != nil && time.Now().Before(r.expires) {
+		defer r.mu.Unlock()
+		return r.index
+	}
+	if r.refreshing == nil {
+		r.refreshing = make(chan struct{})
+		go r.refresh(r.refreshing)
+	}
+	stale, refreshing := r.index, r.refreshing
+	r.mu.Unlock()
+
+	if stale != nil {
+		return stale
+	}
+	select {
+	case <-refreshing:
+	case <-ctx.Done():
+		// caller gave up, known networks are not cached for others
+		return r.buildIndex(nil)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.index
+}
+
+// refresh rebuilds index with platforms requested under own context,
+// so that cancelled request doesn't leave others with known networks only.
+func (r *Registry) refresh(done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), indexRefreshTimeout)
+	defer cancel()
+
+	platforms, err := r.coingeckoProvider.GetAssetPlatforms(ctx)
+	ttl := coingeckoprovider.AssetPlatformsTTL
+	if err != nil {
+		r.logger.Errorf("Can't get asset platforms, using known networks only: %v", err)
+		ttl = indexRetryTTL
+	}
+	idx := r.buildIndex(platforms)
+
+	r.mu.Lock()
+	r.index = idx
+	r.expires = time.Now().Add(ttl)
+	r.refreshing = nil
+	r.mu.Unlock()
+	close(done)
+}
+
+// buildIndex merges coingecko platforms with known networks.
+func (r *Registry) buildIndex(platforms []coingeckoprovider.CoinGeckoAssetPlatform) *index {
+	byPlatform := make(map[string]*models.NetworkResp)
+
+	for _, platform := range platforms {
+		if platform.ID == "" {
+			continue
+		}
+		network := &models.NetworkResp{
+			Platform: platform.ID,
+			ChainID:  platform.ChainIdentifier,
+			Name:     platform.Name,
+			Aliases:  []string{platform.ID},
+			// coingecko sets chain identifier of EVM platforms only, but not of all of them,
+			// so known networks are marked explicitly below
+			EVM: platform.ChainIdentifier != nil,
+		}
+		if platform.ChainIdentifier != nil {
+			network.Aliases = append(network.Aliases, strconv.FormatInt(*platform.ChainIdentifier, 10))
+		}
+		if shortName := strings.ToLower(platform.ShortName); shortName != "" && shortName != platform.ID {
+			network.Aliases = append(network.Aliases, shortName)
+		}
+		byPlatform[platform.ID] = network
+	}
+
+	for _, known := range knownNetworks {
+		network, found := byPlatform[known.platform]
+		if !found {
+			network = &models.NetworkResp{
+				Platform: known.platform,
+				Name:     known.name,
+				Aliases:  []string{known.platform},
+			}
+			byPlatform[known.platform] = network
+		}
+		if network.ChainID == nil {
+			chainID := known.chainID
+			network.ChainID = &chainID
+			network.Aliases = append(network.Aliases, strconv.FormatInt(chainID, 10))
+		}
+		network.EVM = known.evm
+		for _, alias := range known.aliases {
+			if !containsString(network.Aliases, alias) {
+				network.Aliases = append(network.Aliases, alias)
+			}
+		}
+	}
+
+	res := make([]models.NetworkResp, 0, len(byPlatform))
+	for platformID, network := range byPlatform {
+		if explorer, found := r.explorers[platformID]; found {
+			explorer := explorer
+			network.Explorer = &explorer
+		}
+		res = append(res, *network)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Platform < res[j].Platform })
+
+	byAlias := make(map[string]*models.NetworkResp)
+	add := func(alias string, network *models.NetworkResp) {
+		if _, found := byAlias[alias]; !found {
+			byAlias[alias] = network
+		}
+	}
+	byID := make(map[string]*models.NetworkResp, len(res))
+	for i := range res {
+		byID[res[i].Platform] = &res[i]
+	}
+	// colliding aliases are won by platform ids, then by curated aliases of known networks,
+	// then by chain ids and coingecko short names in platform order
+	for i := range res {
+		add(res[i].Platform, &res[i])
+	}
+	for _, known := range knownNetworks {
+		for _, alias := range known.aliases {
+			add(alias, byID[known.platform])
+		}
+	}
+	for i := range res {
+		for _, alias := range res[i].Aliases {
+			add(alias, &res[i])
+		}
+	}
+	// networks list only aliases resolving to them
+	for i := range res {
+		aliases := make([]string, 0, len(res[i].Aliases))
+		for _, alias := range res[i].Aliases {
+			if byAlias[alias] == &res[i] {
+				aliases = append(aliases, alias)
+			}
+		}
+		res[i].Aliases = aliases
+	}
+
+	return &index{networks: res, byAlias: byAlias}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllowBurst(t *testing.T) {
+	l := New(60, 3)
+	now := time.Unix(1000, 0)
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := l.Allow("a", now); !allowed {
+			t.Fatalf("request %d of burst is rejected", i)
+		}
+	}
+	allowed, wait := l.Allow("a", now)
+	if allowed {
+		t.Fatal("request over burst is allowed")
+	}
+	if wait != time.Second {
+		t.Errorf("wait = %v, want 1s", wait)
+	}
+
+	// other keys have own buckets
+	if allowed, _ := l.Allow("b", now); !allowed {
+		t.Error("request of other key is rejected")
+	}
+}
+
+func TestAllowRefill(t *testing.T) {
+	l := New(60, 2)
+	now := time.Unix(1000, 0)
+	l.Allow("a", now)
+	l.Allow("a", now)
+
+	if allowed, wait := l.Allow("a", now.Add(500*time.Millisecond)); allowed || wait != 500*time.Millisecond {
+		t.Errorf("after 0.5s allowed = %v, wait = %v, want false, 500ms", allowed, wait)
+	}
+	if allowed, _ := l.Allow("a", now.Add(time.Second)); !allowed {
+		t.Error("request after refill is rejected")
+	}
+
+	// refill doesn't exceed burst
+	later := now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if allowed, _ := l.Allow("a", later); !allowed {
+			t.Fatalf("request %d after long idle is rejected", i)
+		}
+	}
+	if allowed, _ := l.Allow("a", later); allowed {
+		t.Error("bucket refilled over burst")
+	}
+}
+
+func TestAllowZeroRate(t *testing.T) {
+	l := New(0, 0)
+	now := time.Unix(1000, 0)
+	if allowed, _ := l.Allow("a", now); !allowed {
+		t.Fatal("burst of 1 is rejected")
+	}
+	if allowed, wait := l.Allow("a", now.Add(time.Hour)); allowed || wait != sweepInterval {
+		t.Errorf("zero rate allowed = %v, wait = %v", allowed, wait)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	l := New(60, 1)
+	now := time.Unix(1000, 0)
+	l.Allow("a", now)
+	l.Allow("b", now)
+
+	l.Allow("c", now.Add(sweepInterval))
+	if len(l.buckets) != 1 {
+		t.Errorf("buckets after sweep = %d, want 1", len(l.buckets))
+	}
+}
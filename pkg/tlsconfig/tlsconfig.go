@@ -0,0 +1,193 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"external-metrics/pkg/tools/logging"
+)
+
+// client certificate policies accepted by Options.ClientAuth
+const (
+	ClientAuthRequire       = "require"
+	ClientAuthVerifyIfGiven = "verify_if_given"
+)
+
+// Options configures server TLS.
+type Options struct {
+	CertFile     string
+	KeyFile      string
+	MinVersion   string // 1.0, 1.1, 1.2 or 1.3, 1.2 by default
+	ClientCAFile string // CA of client certificates, empty disables mTLS
+	ClientAuth   string // require (default) or verify_if_given, used with ClientCAFile
+}
+
+// Loader keeps server certificate and client CA loaded from files
+// and reloads them by Watch when the files change.
+type Loader struct {
+	opts       Options
+	minVersion uint16
+	clientAuth tls.ClientAuthType
+	logger     *logging.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+// New loads certificate, key and client CA of opts.
+func New(opts Options, logger *logging.Logger) (*Loader, error) {
+	l := &Loader{opts: opts, logger: logger}
+
+	switch opts.MinVersion {
+	case "1.0":
+		l.minVersion = tls.VersionTLS10
+	case "1.1":
+		l.minVersion = tls.VersionTLS11
+	case "1.2", "":
+		l.minVersion = tls.VersionTLS12
+	case "1.3":
+		l.minVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unknown tls min version %q", opts.MinVersion)
+	}
+
+	switch opts.ClientAuth {
+	case ClientAuthRequire, "":
+		l.clientAuth = tls.RequireAndVerifyClientCert
+	case ClientAuthVerifyIfGiven:
+		l.clientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return nil, fmt.Errorf("unknown tls client auth %q", opts.ClientAuth)
+	}
+
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// TLSConfig returns server config taking current certificate and client CA on every handshake.
+// HTTP/2 is negotiated by ALPN.
+func (l *Loader) TLSConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion:     l.minVersion,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: l.getCertificate,
+	}
+	if l.opts.ClientCAFile != "" {
+		config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			l.mu.RLock()
+			defer l.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:     l.minVersion,
+				NextProtos:     []string{"h2", "http/1.1"},
+				GetCertificate: l.getCertificate,
+				ClientCAs:      l.clientCAs,
+				ClientAuth:     l.clientAuth,
+			}, nil
+		}
+	}
+	return config
+}
+
+// Watch reloads files every interval when any of them changes, until ctx is done.
+// On error previous certificate and CA are kept.
+func (l *Loader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := l.changed()
+		if err != nil {
+			l.logger.Errorf("Can't stat tls files: %v", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		if err = l.load(); err != nil {
+			l.logger.Errorf("Can't reload tls files, keeping previous certificate: %v", err)
+			continue
+		}
+		l.logger.Infof("TLS certificate reloaded from %s", l.opts.CertFile)
+	}
+}
+
+func (l *Loader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.cert, nil
+}
+
+func (l *Loader) files() []string {
+	files := []string{l.opts.CertFile, l.opts.KeyFile}
+	if l.opts.ClientCAFile != "" {
+		files = append(files, l.opts.ClientCAFile)
+	}
+	return files
+}
+
+// changed reports whether modification time of any file differs from loaded one.
+func (l *Loader) changed() (bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, file := range l.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		if !info.ModTime().Equal(l.modTimes[file]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (l *Loader) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range l.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(l.opts.CertFile, l.opts.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load tls key pair: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if l.opts.ClientCAFile != "" {
+		pem, err := os.ReadFile(l.opts.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in client ca file %s", l.opts.ClientCAFile)
+		}
+	}
+
+	l.mu.Lock()
+	l.cert = &cert
+	l.clientCAs = clientCAs
+	l.modTimes = modTimes
+	l.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package tokenlist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Version is semantic version of token list.
+type Version struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+}
+
+// Less reports whether v is older than other.
+func (v Version) Less(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Patch < other.Patch
+}
+
+// Token is token list entry, https://uniswap.org/tokenlist.schema.json
+type Token struct {
+	ChainID    int64                  `json:"chainId"`
+	Address    string                 `json:"address"`
+	Name       string                 `json:"name"`
+	Symbol     string                 `json:"symbol"`
+	Decimals   int                    `json:"decimals"`
+	LogoURI    string                 `json:"logoURI,omitempty"`
+	Tags       []string               `json:"tags,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// TokenList is Uniswap token list, https://uniswap.org/tokenlist.schema.json
+type TokenList struct {
+	Name      string         `json:"name"`
+	Timestamp string         `json:"timestamp"`
+	Version   Version        `json:"version"`
+	LogoURI   string         `json:"logoURI,omitempty"`
+	Keywords  []string       `json:"keywords,omitempty"`
+	Tags      map[string]Tag `json:"tags,omitempty"`
+	Tokens    []Token        `json:"tokens"`
+}
+
+// Tag describes token tag.
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Registry keeps tokens merged from token list files.
+type Registry struct {
+	list   TokenList
+	byAddr map[string]int // tokenKey -> index in list.Tokens
+}
+
+func tokenKey(chainID int64, address string) string {
+	return fmt.Sprintf("%d_%s", chainID, strings.ToLower(address))
+}
+
+// Load reads token list files and merges them into one list named name.
+// Tokens of later files override tokens with the same chain id and address of earlier ones.
+//
+// Version of the list is derived from its tokens compared with the last served list kept in
+// stateFile: removed tokens bump major, added tokens bump minor, changed tokens bump patch.
+// Version is never older than versions of the files. Without stateFile version of the file is
+// served as is, so merging several files requires stateFile.
+func Load(name string, files []string, stateFile string) (*Registry, error) {
+	if len(files) > 1 && stateFile == "" {
+		return nil, fmt.Errorf("state file is required to version list merged from %d files", len(files))
+	}
+
+	r := &Registry{
+		list: TokenList{
+			Name:   name,
+			Tokens: make([]Token, 0),
+			Tags:   make(map[string]Tag),
+		},
+		byAddr: make(map[string]int),
+	}
+
+	var timestamp time.Time
+	for _, file := range files {
+		list, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.list.Version.Less(list.Version) {
+			r.list.Version = list.Version
+		}
+		if ts, err := time.Parse(time.RFC3339, list.Timestamp); err == nil && ts.After(timestamp) {
+			timestamp = ts
+		}
+		for tag, description := range list.Tags {
+			r.list.Tags[tag] = description
+		}
+
+		for _, token := range list.Tokens {
+			key := tokenKey(token.ChainID, token.Address)
+			if i, found := r.byAddr[key]; found {
+				r.list.Tokens[i] = token
+				continue
+			}
+			r.byAddr[key] = len(r.list.Tokens)
+			r.list.Tokens = append(r.list.Tokens, token)
+		}
+	}
+
+	if len(files) > 1 {
+		// merged list is newer than each file
+		r.list.Version.Patch++
+	}
+	if timestamp.IsZero() {
+		timestamp = time.Now().UTC()
+	}
+	r.list.Timestamp = timestamp.Format(time.RFC3339)
+
+	if stateFile == "" {
+		return r, nil
+	}
+	if err := r.applyState(stateFile); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// applyState versions the list against the last served list in stateFile and saves it there when version moves.
+func (r *Registry) applyState(stateFile string) error {
+	last, err := readFile(stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		// first served list
+		return writeFile(stateFile, r.list)
+	}
+	if err != nil {
+		return err
+	}
+
+	filesVersion := r.list.Version
+	r.list.Version = bumpVersion(last.Version, diffTokens(last.Tokens, r.list.Tokens))
+	if r.list.Version.Less(filesVersion) {
+		r.list.Version = filesVersion
+	}
+	if r.list.Version == last.Version {
+		r.list.Timestamp = last.Timestamp
+		return nil
+	}
+	r.list.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	return writeFile(stateFile, r.list)
+}
+
+// change is kind of token list change, as semantic version part it bumps.
+type change int
+
+const (
+	noChange change = iota
+	patchChange
+	minorChange
+	majorChange
+)
+
+// diffTokens returns the most significant change from last tokens to tokens.
+func diffTokens(last, tokens []Token) change {
+	res := noChange
+	lastByAddr := make(map[string]Token, len(last))
+	for _, token := range last {
+		lastByAddr[tokenKey(token.ChainID, token.Address)] = token
+	}
+	for _, token := range tokens {
+		key := tokenKey(token.ChainID, token.Address)
+		lastToken, found := lastByAddr[key]
+		if !found {
+			res = minorChange
+			continue
+		}
+		delete(lastByAddr, key)
+		if res == noChange && !sameToken(lastToken, token) {
+			res = patchChange
+		}
+	}
+	if len(lastByAddr) > 0 {
+		return majorChange
+	}
+	return res
+}
+
+// sameToken compares tokens by JSON form, which doesn't tell nil from empty optional fields.
+func sameToken(a, b Token) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
+
+func bumpVersion(v Version, c change) Version {
+	switch c {
+	case majorChange:
+		return Version{Major: v.Major + 1}
+	case minorChange:
+		return Version{Major: v.Major, Minor: v.Minor + 1}
+	case patchChange:
+		v.Patch++
+	}
+	return v
+}
+
+func readFile(file string) (*TokenList, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var list TokenList
+	if err = json.NewDecoder(f).Decode(&list); err != nil {
+		return nil, fmt.Errorf("can't decode token list %s: %w", file, err)
+	}
+	for i, token := range list.Tokens {
+		if token.ChainID == 0 || token.Address == "" {
+			return nil, fmt.Errorf("token list %s: token #%d has no chainId or address", file, i)
+		}
+	}
+	return &list, nil
+}
+
+// writeFile replaces file with list atomically.
+func writeFile(file string, list TokenList) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("can't write token list state: %w", err)
+	}
+	if err = os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("can't write token list state: %w", err)
+	}
+	return nil
+}
+
+// List returns copy of merged token list.
+func (r *Registry) List() TokenList {
+	list := r.list
+	list.Tokens = make([]Token, len(r.list.Tokens))
+	copy(list.Tokens, r.list.Tokens)
+	return list
+}
+
+// Lookup returns token by chain id and address (case-insensitive).
+func (r *Registry) Lookup(chainID int64, address string) (Token, bool) {
+	if r == nil {
+		return Token{}, false
+	}
+	i, found := r.byAddr[tokenKey(chainID, address)]
+	if !found {
+		return Token{}, false
+	}
+	return r.list.Tokens[i], true
+}
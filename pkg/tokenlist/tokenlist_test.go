@@ -0,0 +1,79 @@
+package tokenlist
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeList(t *testing.T, file string, version Version, tokens ...Token) {
+	t.Helper()
+	data, err := json.Marshal(TokenList{Name: "test", Version: version, Tokens: tokens})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadVersion(t *testing.T) {
+	dir := t.TempDir()
+	base, extra, state := filepath.Join(dir, "base.json"), filepath.Join(dir, "extra.json"), filepath.Join(dir, "state.json")
+
+	usdc := Token{ChainID: 1, Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Symbol: "USDC", Decimals: 6}
+	dai := Token{ChainID: 1, Address: "0x6B175474E89094C44Da98b954EedeAC495271d0F", Symbol: "DAI", Decimals: 18}
+	weth := Token{ChainID: 1, Address: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", Symbol: "WETH", Decimals: 18}
+	writeList(t, base, Version{Major: 2, Minor: 5}, usdc, dai)
+	writeList(t, extra, Version{Major: 1}, weth)
+
+	steps := []struct {
+		name   string
+		update func()
+		want   Version
+	}{
+		{"first load", func() {}, Version{Major: 2, Minor: 5, Patch: 1}},
+		{"unchanged", func() {}, Version{Major: 2, Minor: 5, Patch: 1}},
+		{"changed token of older file", func() {
+			weth.Name = "Wrapped Ether"
+			writeList(t, extra, Version{Major: 1}, weth)
+		}, Version{Major: 2, Minor: 5, Patch: 2}},
+		{"added token", func() {
+			writeList(t, extra, Version{Major: 1}, weth, Token{ChainID: 56, Address: "0x55d398326f99059fF775485246999027B3197955"})
+		}, Version{Major: 2, Minor: 6}},
+		{"removed token", func() {
+			writeList(t, extra, Version{Major: 1}, weth)
+		}, Version{Major: 3}},
+		{"newer file version", func() {
+			writeList(t, base, Version{Major: 4, Minor: 1}, usdc, dai)
+		}, Version{Major: 4, Minor: 1, Patch: 1}},
+	}
+	for _, step := range steps {
+		step.update()
+		r, err := Load("test", []string{base, extra}, state)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got := r.List().Version; got != step.want {
+			t.Errorf("%s: version = %+v, want %+v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestLoadRequiresState(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "list.json")
+	writeList(t, file, Version{Major: 1}, Token{ChainID: 1, Address: "0x6B175474E89094C44Da98b954EedeAC495271d0F"})
+
+	if _, err := Load("test", []string{file, file}, ""); err == nil {
+		t.Error("several files are merged without state file")
+	}
+	r, err := Load("test", []string{file}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.List().Version; got != (Version{Major: 1}) {
+		t.Errorf("single file version = %+v, want 1.0.0", got)
+	}
+}
@@ -0,0 +1,114 @@
+package cachecontrol
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// immutableMaxAge is max-age of responses built only from data cached without expiration
+// (coin index). It is capped to a day, so that fixes of upstream data reach clients.
+const immutableMaxAge = 24 * time.Hour
+
+// Freshness collects how long data used for a response stays fresh.
+// Response is fresh for the shortest of observed TTLs.
+type Freshness struct {
+	mu        sync.Mutex
+	observed  bool
+	maxAge    time.Duration
+	immutable bool
+}
+
+type ctxKey struct{}
+
+// NewContext returns ctx carrying new Freshness.
+func NewContext(ctx context.Context) (context.Context, *Freshness) {
+	freshness := &Freshness{}
+	return context.WithValue(ctx, ctxKey{}, freshness), freshness
+}
+
+// FromContext returns Freshness of ctx or nil.
+func FromContext(ctx context.Context) *Freshness {
+	freshness, _ := ctx.Value(ctxKey{}).(*Freshness)
+	return freshness
+}
+
+// Observe records that data used for response of ctx stays fresh for ttl.
+func Observe(ctx context.Context, ttl time.Duration) {
+	freshness := FromContext(ctx)
+	if freshness == nil {
+		return
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	freshness.mu.Lock()
+	defer freshness.mu.Unlock()
+	if !freshness.observed || ttl < freshness.maxAge {
+		freshness.maxAge = ttl
+	}
+	freshness.observed = true
+}
+
+// ObserveImmutable records that data used for response of ctx never changes.
+func ObserveImmutable(ctx context.Context) {
+	freshness := FromContext(ctx)
+	if freshness == nil {
+		return
+	}
+
+	freshness.mu.Lock()
+	defer freshness.mu.Unlock()
+	freshness.immutable = true
+}
+
+// Header returns Cache-Control value: max-age of the shortest observed TTL,
+// long max-age if only immutable data was used and no-cache if nothing was observed.
+func (f *Freshness) Header() string {
+	if f == nil {
+		return "no-cache"
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case f.observed && f.maxAge >= time.Second:
+		return fmt.Sprintf("public, max-age=%d", int(f.maxAge.Seconds()))
+	case !f.observed && f.immutable:
+		return fmt.Sprintf("public, max-age=%d, immutable", int(immutableMaxAge.Seconds()))
+	default:
+		return "no-cache"
+	}
+}
+
+// MaxAge returns remaining freshness of upstream response by its Cache-Control and Age headers.
+// Response without max-age or with no-cache or no-store is not fresh.
+func MaxAge(header http.Header) time.Duration {
+	var maxAge time.Duration
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-cache", directive == "no-store":
+			return 0
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err != nil {
+				return 0
+			}
+			maxAge = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if age, err := strconv.Atoi(header.Get("Age")); err == nil {
+		maxAge -= time.Duration(age) * time.Second
+	}
+	if maxAge < 0 {
+		return 0
+	}
+	return maxAge
+}
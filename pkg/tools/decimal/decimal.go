@@ -0,0 +1,194 @@
+package decimal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numberRe matches JSON number literal.
+var numberRe = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// limits of literal accepted by New, so that exact arithmetic on decimals stays cheap
+const (
+	maxExponent       = 400
+	maxFractionDigits = 64
+)
+
+// Decimal is a decimal number kept as its literal, so that values decoded from JSON
+// are encoded back without float rounding. Zero value is 0.
+type Decimal struct {
+	value string
+}
+
+// New parses JSON number literal s into Decimal.
+// Exponent is limited to maxExponent and fraction to maxFractionDigits digits.
+func New(s string) (Decimal, error) {
+	match := numberRe.FindStringSubmatch(s)
+	if match == nil {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	if len(match[2]) > maxFractionDigits+1 {
+		return Decimal{}, fmt.Errorf("decimal %q has more than %d fraction digits", s, maxFractionDigits)
+	}
+	if match[3] != "" {
+		// exponent up to 3 digits after sign and leading zeros
+		digits := strings.TrimLeft(strings.TrimLeft(match[3][1:], "+-"), "0")
+		exp, err := strconv.Atoi(digits)
+		if digits != "" && (err != nil || len(digits) > 3 || exp > maxExponent) {
+			return Decimal{}, fmt.Errorf("decimal %q exponent is out of range", s)
+		}
+	}
+	return Decimal{value: s}, nil
+}
+
+// NewFromFloat returns the shortest Decimal representing f.
+func NewFromFloat(f float64) Decimal {
+	return Decimal{value: strconv.FormatFloat(f, 'f', -1, 64)}
+}
+
+// String returns decimal literal.
+func (d Decimal) String() string {
+	if d.value == "" {
+		return "0"
+	}
+	return d.value
+}
+
+// IsZero reports whether d equals 0.
+func (d Decimal) IsZero() bool {
+	return d.Rat().Sign() == 0
+}
+
+// Rat returns exact rational value of d, 0 if d literal can't be parsed.
+func (d Decimal) Rat() *big.Rat {
+	r, ok := new(big.Rat).SetString(d.String())
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// Float64 returns the nearest float64 value of d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Mul returns exact product of d and e.
+func (d Decimal) Mul(e Decimal) Decimal {
+	product := new(big.Rat).Mul(d.Rat(), e.Rat())
+	return Decimal{value: trimFraction(product.FloatString(d.scale() + e.scale()))}
+}
+
+// quoPrecision is number of significant digits of Quo result.
+const quoPrecision = 18
+
+// Quo returns d / e rounded to quoPrecision significant digits. e must not be zero.
+func (d Decimal) Quo(e Decimal) Decimal {
+	quotient := new(big.Rat).Quo(d.Rat(), e.Rat())
+	if quotient.Sign() == 0 {
+		return Decimal{}
+	}
+
+	// decimal exponent of the first significant digit
+	exp := 0
+	text := new(big.Float).SetPrec(128).SetRat(quotient).Text('e', quoPrecision-1)
+	if i := strings.IndexByte(text, 'e'); i >= 0 {
+		exp, _ = strconv.Atoi(text[i+1:])
+	}
+	scale := quoPrecision - 1 - exp
+	if scale < 0 {
+		scale = 0
+	}
+	return Decimal{value: trimFraction(quotient.FloatString(scale))}
+}
+
+// trimFraction removes trailing zeros of fraction.
+func trimFraction(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
+
+// scale returns number of fraction digits of d without exponent.
+func (d Decimal) scale() int {
+	mantissa, exp := strings.ToLower(d.String()), 0
+	if i := strings.IndexByte(mantissa, 'e'); i >= 0 {
+		exp, _ = strconv.Atoi(mantissa[i+1:])
+		mantissa = mantissa[:i]
+	}
+	scale := 0
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		scale = len(mantissa) - i - 1
+	}
+	if scale -= exp; scale < 0 {
+		return 0
+	}
+	return scale
+}
+
+// MarshalJSON encodes d as JSON number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes JSON number, numeric string or null (as 0).
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*d = Decimal{}
+		return nil
+	}
+
+	literal := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &literal); err != nil {
+			return err
+		}
+	}
+
+	res, err := New(literal)
+	if err != nil {
+		return err
+	}
+	*d = res
+	return nil
+}
+
+// NumbersToStrings returns JSON representation of v with every number replaced by
+// its literal string, for clients that can't decode numbers without precision loss (JavaScript).
+func NumbersToStrings(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var res interface{}
+	if err = decoder.Decode(&res); err != nil {
+		return nil, err
+	}
+	return stringifyNumbers(res), nil
+}
+
+func stringifyNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		return v.String()
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = stringifyNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = stringifyNumbers(item)
+		}
+	}
+	return v
+}
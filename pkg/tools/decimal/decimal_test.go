@@ -0,0 +1,139 @@
+package decimal
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		literal string
+		valid   bool
+	}{
+		{"0", true},
+		{"-1.5", true},
+		{"123.456e-7", true},
+		{"1E+400", true},
+		{"1e-400", true},
+		{"1e-0000000000400", true},
+		{"0." + strings.Repeat("1", 64), true},
+		{"", false},
+		{"NaN", false},
+		{"Inf", false},
+		{"+1", false},
+		{"01", false},
+		{"1.", false},
+		{"1e401", false},
+		{"1e-401", false},
+		{"0e-2000000000", false},
+		{"1e99999999999999999999", false},
+		{"0." + strings.Repeat("1", 65), false},
+	}
+	for _, test := range tests {
+		_, err := New(test.literal)
+		if (err == nil) != test.valid {
+			t.Errorf("New(%q) error = %v, want valid %v", test.literal, err, test.valid)
+		}
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var d Decimal
+	if d.String() != "0" || !d.IsZero() || d.Rat().Sign() != 0 {
+		t.Errorf("zero value = %q, IsZero %v", d.String(), d.IsZero())
+	}
+	// literal not made by New must not panic
+	bad := Decimal{value: "not a number"}
+	if !bad.IsZero() {
+		t.Errorf("invalid literal is not zero")
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		a, b, want string
+	}{
+		{"1.5", "2", "3"},
+		{"1e3", "0.25", "250"},
+		{"1e-3", "12.5", "0.0125"},
+		{"0", "3", "0"},
+		{"100", "1.10", "110"},
+		{"-2", "0.5", "-1"},
+		{"0e-400", "1e-400", "0"},
+		{"1e-400", "1e-400", "0." + strings.Repeat("0", 799) + "1"},
+	}
+	for _, test := range tests {
+		a, b := mustNew(t, test.a), mustNew(t, test.b)
+		if got := a.Mul(b).String(); got != test.want {
+			t.Errorf("%s * %s = %s, want %s", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestQuo(t *testing.T) {
+	tests := []struct {
+		a, b, want string
+	}{
+		{"6", "2", "3"},
+		{"1", "3", "0.333333333333333333"},
+		{"1", "60000", "0.0000166666666666666667"},
+		{"1e-10", "3", "0.0000000000333333333333333333"},
+		{"123456789012345678901", "7", "17636684144620811272"},
+		{"0", "5", "0"},
+	}
+	for _, test := range tests {
+		a, b := mustNew(t, test.a), mustNew(t, test.b)
+		if got := a.Quo(b).String(); got != test.want {
+			t.Errorf("%s / %s = %s, want %s", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		A Decimal `json:"a"`
+		B Decimal `json:"b"`
+		C Decimal `json:"c"`
+	}
+	if err := json.Unmarshal([]byte(`{"a": 0.1000000000000000055511, "b": "12.5", "c": null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":0.1000000000000000055511,"b":12.5,"c":0}`; string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+
+	for _, input := range []string{`{"a": "1e-2000000000"}`, `{"a": "abc"}`, `{"a": 1e500}`} {
+		if err := json.Unmarshal([]byte(input), &v); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded", input)
+		}
+	}
+}
+
+func TestNumbersToStrings(t *testing.T) {
+	res, err := NumbersToStrings(map[string]interface{}{
+		"price": mustNew(t, "0.1000000000000000055511"),
+		"list":  []int{1, 2},
+		"name":  "btc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(res)
+	if want := `{"list":["1","2"],"name":"btc","price":"0.1000000000000000055511"}`; string(data) != want {
+		t.Errorf("NumbersToStrings = %s, want %s", data, want)
+	}
+}
+
+func mustNew(t *testing.T, s string) Decimal {
+	t.Helper()
+	d, err := New(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
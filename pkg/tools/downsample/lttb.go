@@ -0,0 +1,81 @@
+package downsample
+
+import "math"
+
+// MinThreshold is the least number of points LTTB can reduce a series to.
+const MinThreshold = 3
+
+// LTTB returns indices of points chosen by Largest-Triangle-Three-Buckets algorithm
+// to reduce series of [x, y] points to threshold points.
+// First and last points are always kept, as well as global minimum and maximum of y:
+// the bucket containing an extreme returns the extreme instead of the LTTB choice
+// (if both extremes fall into one bucket the earlier of them is kept).
+// If threshold is less than MinThreshold or not less than series length all indices are returned.
+func LTTB(points [][]float64, threshold int) []int {
+	n := len(points)
+	if threshold < MinThreshold || threshold >= n {
+		indices := make([]int, n)
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices
+	}
+
+	minIdx, maxIdx := 0, 0
+	for i, point := range points {
+		if point[1] < points[minIdx][1] {
+			minIdx = i
+		}
+		if point[1] > points[maxIdx][1] {
+			maxIdx = i
+		}
+	}
+
+	indices := make([]int, 0, threshold)
+	indices = append(indices, 0)
+
+	// all points except first and last are split into threshold-2 buckets
+	bucketSize := float64(n-2) / float64(threshold-2)
+	prev := 0
+	for bucket := 0; bucket < threshold-2; bucket++ {
+		start := int(math.Floor(float64(bucket)*bucketSize)) + 1
+		end := int(math.Floor(float64(bucket+1)*bucketSize)) + 1
+		if end > n-1 {
+			end = n - 1
+		}
+
+		// average point of the next bucket is the third triangle vertex
+		nextStart := end
+		nextEnd := int(math.Floor(float64(bucket+2)*bucketSize)) + 1
+		if nextEnd > n {
+			nextEnd = n
+		}
+		var avgX, avgY float64
+		for i := nextStart; i < nextEnd; i++ {
+			avgX += points[i][0]
+			avgY += points[i][1]
+		}
+		avgX /= float64(nextEnd - nextStart)
+		avgY /= float64(nextEnd - nextStart)
+
+		chosen, maxArea := start, -1.0
+		for i := start; i < end; i++ {
+			if i == minIdx || i == maxIdx {
+				chosen = i
+				break
+			}
+			area := math.Abs(
+				(points[prev][0]-avgX)*(points[i][1]-points[prev][1]) -
+					(points[prev][0]-points[i][0])*(avgY-points[prev][1]),
+			)
+			if area > maxArea {
+				chosen, maxArea = i, area
+			}
+		}
+
+		indices = append(indices, chosen)
+		prev = chosen
+	}
+
+	return append(indices, n-1)
+}
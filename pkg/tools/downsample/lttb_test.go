@@ -0,0 +1,83 @@
+package downsample
+
+import (
+	"math"
+	"testing"
+)
+
+func series(n int, y func(i int) float64) [][]float64 {
+	points := make([][]float64, n)
+	for i := range points {
+		points[i] = []float64{float64(i * 1000), y(i)}
+	}
+	return points
+}
+
+func TestLTTBKeepsAllPoints(t *testing.T) {
+	points := series(10, func(i int) float64 { return float64(i) })
+	for _, threshold := range []int{0, 2, 10, 20} {
+		indices := LTTB(points, threshold)
+		if len(indices) != len(points) {
+			t.Errorf("threshold %d: got %d points, want %d", threshold, len(indices), len(points))
+		}
+	}
+	if indices := LTTB(nil, 5); len(indices) != 0 {
+		t.Errorf("empty series: got %v", indices)
+	}
+}
+
+func TestLTTB(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    [][]float64
+		threshold int
+	}{
+		{"sine", series(1000, func(i int) float64 { return math.Sin(float64(i) / 50) }), 100},
+		{"line", series(500, func(i int) float64 { return float64(i) }), 3},
+		{"spike", series(300, func(i int) float64 {
+			if i == 137 {
+				return 1000
+			}
+			return 1
+		}), 10},
+		{"extremes at ends", series(200, func(i int) float64 { return float64(i * i) }), 7},
+	}
+	for _, test := range tests {
+		indices := LTTB(test.points, test.threshold)
+		if len(indices) != test.threshold {
+			t.Errorf("%s: got %d points, want %d", test.name, len(indices), test.threshold)
+			continue
+		}
+		if indices[0] != 0 || indices[len(indices)-1] != len(test.points)-1 {
+			t.Errorf("%s: first and last points are not kept: %v", test.name, indices)
+		}
+		for i := 1; i < len(indices); i++ {
+			if indices[i] <= indices[i-1] {
+				t.Errorf("%s: indices are not increasing: %v", test.name, indices)
+				break
+			}
+		}
+
+		minIdx, maxIdx := 0, 0
+		for i, point := range test.points {
+			if point[1] < test.points[minIdx][1] {
+				minIdx = i
+			}
+			if point[1] > test.points[maxIdx][1] {
+				maxIdx = i
+			}
+		}
+		if !contains(indices, minIdx) || !contains(indices, maxIdx) {
+			t.Errorf("%s: extremes %d and %d are not kept: %v", test.name, minIdx, maxIdx, indices)
+		}
+	}
+}
+
+func contains(list []int, v int) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
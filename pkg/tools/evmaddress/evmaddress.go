@@ -0,0 +1,58 @@
+package evmaddress
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Normalize validates EVM address and returns it lower-cased.
+// Address must be 0x-prefixed 40 hex characters. Mixed-case address must have valid
+// EIP-55 checksum, all lower-case and all upper-case addresses are accepted without it.
+func Normalize(address string) (string, error) {
+	if !strings.HasPrefix(address, "0x") && !strings.HasPrefix(address, "0X") {
+		return "", fmt.Errorf("address %q must start with 0x", address)
+	}
+	digits := address[2:]
+	if len(digits) != 40 {
+		return "", fmt.Errorf("address %q must have 40 hex characters after 0x, got %d", address, len(digits))
+	}
+	if _, err := hex.DecodeString(digits); err != nil {
+		return "", fmt.Errorf("address %q contains non-hex characters", address)
+	}
+
+	lower := "0x" + strings.ToLower(digits)
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && Checksum(lower) != "0x"+digits {
+		return "", fmt.Errorf("address %q has invalid EIP-55 checksum, expected %s", address, Checksum(lower))
+	}
+
+	return lower, nil
+}
+
+// Checksum returns EIP-55 mixed-case form of valid address.
+func Checksum(address string) string {
+	digits := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X"))
+
+	hash := sha3.NewLegacyKeccak256()
+	_, _ = hash.Write([]byte(digits))
+	hashHex := hex.EncodeToString(hash.Sum(nil))
+
+	res := []byte(digits)
+	for i, c := range res {
+		if c >= 'a' && c <= 'f' && hashHex[i] >= '8' {
+			res[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(res)
+}
+
+// IsAddress reports whether s is well-formed EVM address regardless of its checksum.
+func IsAddress(s string) bool {
+	if len(s) != 42 || (s[:2] != "0x" && s[:2] != "0X") {
+		return false
+	}
+	_, err := hex.DecodeString(s[2:])
+	return err == nil
+}
@@ -0,0 +1,191 @@
+package htmltext
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	tagRe  = regexp.MustCompile(`(?s)<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*)>|<!--.*?-->`)
+	hrefRe = regexp.MustCompile(`(?i)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+	blankLinesRe = regexp.MustCompile(`\n{3,}`)
+)
+
+// allowedTags are kept by Sanitize, without attributes except a[href].
+var allowedTags = map[string]bool{
+	"a": true, "p": true, "br": true, "b": true, "strong": true, "i": true, "em": true,
+	"ul": true, "ol": true, "li": true, "h1": true, "h2": true, "h3": true, "h4": true,
+}
+
+// droppedTags are removed together with their content.
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true, "noscript": true,
+}
+
+// token is either text (tag is empty) or html tag.
+type token struct {
+	text    string // unescaped text
+	tag     string // lower-cased tag name
+	closing bool
+	href    string // safe href of <a>
+}
+
+func tokenize(s string) []token {
+	tokens := make([]token, 0)
+	dropping := ""
+	pos := 0
+	for _, m := range tagRe.FindAllStringSubmatchIndex(s, -1) {
+		if dropping == "" && m[0] > pos {
+			tokens = append(tokens, token{text: html.UnescapeString(s[pos:m[0]])})
+		}
+		pos = m[1]
+
+		if m[4] < 0 { // comment
+			continue
+		}
+		closing := s[m[2]:m[3]] == "/"
+		tag := strings.ToLower(s[m[4]:m[5]])
+
+		if dropping != "" {
+			if closing && tag == dropping {
+				dropping = ""
+			}
+			continue
+		}
+		if droppedTags[tag] {
+			if !closing && !strings.HasSuffix(s[m[6]:m[7]], "/") {
+				dropping = tag
+			}
+			continue
+		}
+
+		t := token{tag: tag, closing: closing}
+		if tag == "a" && !closing {
+			t.href = safeHref(s[m[6]:m[7]])
+		}
+		tokens = append(tokens, t)
+	}
+	if dropping == "" && pos < len(s) {
+		tokens = append(tokens, token{text: html.UnescapeString(s[pos:])})
+	}
+	return tokens
+}
+
+// safeHref returns http(s) link from tag attributes or empty string.
+func safeHref(attrs string) string {
+	m := hrefRe.FindStringSubmatch(attrs)
+	if m == nil {
+		return ""
+	}
+	href := strings.TrimSpace(html.UnescapeString(m[1] + m[2] + m[3]))
+	lower := strings.ToLower(href)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		return ""
+	}
+	return href
+}
+
+// Sanitize returns html with only basic formatting tags and http(s) links kept,
+// everything else is stripped or escaped.
+func Sanitize(s string) string {
+	var b strings.Builder
+	for _, t := range tokenize(s) {
+		switch {
+		case t.tag == "":
+			b.WriteString(html.EscapeString(t.text))
+		case !allowedTags[t.tag]:
+		case t.closing:
+			b.WriteString("</" + t.tag + ">")
+		case t.tag == "a" && t.href != "":
+			b.WriteString(`<a href="` + html.EscapeString(t.href) + `" rel="nofollow noopener noreferrer" target="_blank">`)
+		default:
+			b.WriteString("<" + t.tag + ">")
+		}
+	}
+	return b.String()
+}
+
+// ToText returns plain text of html keeping line breaks of paragraphs and lists.
+func ToText(s string) string {
+	var b strings.Builder
+	for _, t := range tokenize(s) {
+		switch {
+		case t.tag == "":
+			b.WriteString(t.text)
+		case t.tag == "br", t.closing && (t.tag == "p" || t.tag == "li" || isHeading(t.tag)):
+			b.WriteString("\n")
+		case !t.closing && t.tag == "li":
+			b.WriteString("- ")
+		}
+	}
+	return normalizeLines(b.String())
+}
+
+// ToMarkdown converts html to markdown: links, emphasis, headers and lists are kept.
+func ToMarkdown(s string) string {
+	var b strings.Builder
+	hrefs := make([]string, 0)
+	for _, t := range tokenize(s) {
+		switch t.tag {
+		case "":
+			b.WriteString(escapeMarkdown(t.text))
+		case "a":
+			if !t.closing {
+				hrefs = append(hrefs, t.href)
+				if t.href != "" {
+					b.WriteString("[")
+				}
+			} else if len(hrefs) > 0 {
+				href := hrefs[len(hrefs)-1]
+				hrefs = hrefs[:len(hrefs)-1]
+				if href != "" {
+					b.WriteString("](" + strings.ReplaceAll(href, ")", "%29") + ")")
+				}
+			}
+		case "b", "strong":
+			b.WriteString("**")
+		case "i", "em":
+			b.WriteString("_")
+		case "br":
+			b.WriteString("  \n")
+		case "p":
+			if t.closing {
+				b.WriteString("\n\n")
+			}
+		case "li":
+			if t.closing {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("- ")
+			}
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			if t.closing {
+				b.WriteString("\n\n")
+			} else {
+				b.WriteString(strings.Repeat("#", int(t.tag[1]-'0')) + " ")
+			}
+		}
+	}
+	return normalizeLines(b.String())
+}
+
+// isHeading reports whether tag is h1-h6.
+func isHeading(tag string) bool {
+	return len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6'
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "`", "\\`", "<", "&lt;", ">", "&gt;",
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func normalizeLines(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = blankLinesRe.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
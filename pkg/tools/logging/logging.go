@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -12,88 +13,225 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// log formats accepted by New
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+const timestampFormat = "2006-01-02 15:04:05"
+
 // InitLogger init the logrus.Logger for defined log level.
 func InitLogger(level logrus.Level) *logrus.Logger {
 	return &logrus.Logger{
 		Out: os.Stdout,
 		Formatter: &logrus.JSONFormatter{
 			CallerPrettyfier: caller,
-			TimestampFormat:  "2006-01-02 15:04:05",
+			TimestampFormat:  timestampFormat,
 		},
 		ReportCaller: true,
 		Level:        level,
 	}
 }
 
+// Logger wraps logrus.Logger. Every line gets "file" field with caller of Logger method,
+// scoped loggers made by WithField(s) and WithError add their fields too.
 type Logger struct {
 	Logger *logrus.Logger
+	entry  *logrus.Entry // fields of scoped logger, nil for root logger
 }
 
-// InitLoggerTwo
+// InitLoggerNew returns JSON logger of level writing to out.
 func InitLoggerNew(level logrus.Level, out io.Writer) *Logger {
+	logger, _ := New(level, FormatJSON, out)
+	return logger
+}
+
+// New returns logger of level writing lines of format (json or text, json if empty) to out.
+func New(level logrus.Level, format string, out io.Writer) (*Logger, error) {
+	var formatter logrus.Formatter
+	switch format {
+	case FormatJSON, "":
+		formatter = &logrus.JSONFormatter{TimestampFormat: timestampFormat}
+	case FormatText:
+		formatter = &logrus.TextFormatter{FullTimestamp: true, TimestampFormat: timestampFormat}
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
 
 	return &Logger{
 		Logger: &logrus.Logger{
-			Out: out,
-			Formatter: &logrus.JSONFormatter{
-				CallerPrettyfier: caller,
-				TimestampFormat:  "2006-01-02 15:04:05",
-			},
-			ReportCaller: true,
-			Level:        level,
+			Out:       out,
+			Formatter: formatter,
+			Hooks:     make(logrus.LevelHooks),
+			Level:     level,
+			ExitFunc:  os.Exit,
 		},
+	}, nil
+}
+
+// WithField returns logger adding field to every line of l.
+func (l *Logger) WithField(key string, value interface{}) *Logger {
+	return &Logger{Logger: l.Logger, entry: l.base().WithField(key, value)}
+}
+
+// WithFields returns logger adding fields to every line of l.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	return &Logger{Logger: l.Logger, entry: l.base().WithFields(fields)}
+}
+
+// WithError returns logger adding error field to every line of l.
+func (l *Logger) WithError(err error) *Logger {
+	return &Logger{Logger: l.Logger, entry: l.base().WithError(err)}
+}
+
+// base returns entry with fields of l.
+func (l *Logger) base() *logrus.Entry {
+	if l.entry != nil {
+		return l.entry
+	}
+	return logrus.NewEntry(l.Logger)
+}
+
+// log returns entry with fields of l and caller of Logger method.
+// logrus ReportCaller would point to this file, so caller is taken here skipping the wrapper.
+func (l *Logger) log() *logrus.Entry {
+	entry := l.base()
+	if frame, ok := callerFrame(3); ok {
+		_, file := caller(frame)
+		entry = entry.WithField(logrus.FieldKeyFile, file)
 	}
+	return entry
+}
+
+// Trace
+func (l *Logger) Trace(args ...interface{}) {
+	l.log().Trace(args...)
+}
+
+// Tracef
+func (l *Logger) Tracef(format string, args ...interface{}) {
+	l.log().Tracef(format, args...)
+}
+
+// Traceln
+func (l *Logger) Traceln(args ...interface{}) {
+	l.log().Traceln(args...)
+}
 
+// Debug
+func (l *Logger) Debug(args ...interface{}) {
+	l.log().Debug(args...)
+}
+
+// Debugf
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.log().Debugf(format, args...)
+}
+
+// Debugln
+func (l *Logger) Debugln(args ...interface{}) {
+	l.log().Debugln(args...)
 }
 
 // Info
 func (l *Logger) Info(args ...interface{}) {
-	l.Logger.Info(args...)
+	l.log().Info(args...)
 }
 
 // Infof
 func (l *Logger) Infof(format string, args ...interface{}) {
-	l.Logger.Infof(format, args...)
+	l.log().Infof(format, args...)
 }
 
 // Infoln
 func (l *Logger) Infoln(args ...interface{}) {
-	l.Logger.Infoln(args...)
+	l.log().Infoln(args...)
+}
+
+// Warn
+func (l *Logger) Warn(args ...interface{}) {
+	l.log().Warn(args...)
+}
+
+// Warnf
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.log().Warnf(format, args...)
+}
+
+// Warnln
+func (l *Logger) Warnln(args ...interface{}) {
+	l.log().Warnln(args...)
 }
 
 // Error
 func (l *Logger) Error(args ...interface{}) {
-	l.Logger.Error(args...)
+	l.log().Error(args...)
 }
 
 // Errorf
 func (l *Logger) Errorf(format string, args ...interface{}) {
-	l.Logger.Errorf(format, args...)
+	l.log().Errorf(format, args...)
 }
 
 // Errorln
 func (l *Logger) Errorln(args ...interface{}) {
-	l.Logger.Errorln(args...)
+	l.log().Errorln(args...)
 }
 
-// Debug
-func (l *Logger) Debug(args ...interface{}) {
-	l.Logger.Debug(args...)
+// Fatal
+func (l *Logger) Fatal(args ...interface{}) {
+	l.log().Fatal(args...)
 }
 
-// Debugf
-func (l *Logger) Debugf(format string, args ...interface{}) {
-	l.Logger.Debugf(format, args...)
+// Fatalf
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.log().Fatalf(format, args...)
 }
 
-// Debugln
-func (l *Logger) Debugln(args ...interface{}) {
-	l.Logger.Debugln(args...)
+// Fatalln
+func (l *Logger) Fatalln(args ...interface{}) {
+	l.log().Fatalln(args...)
 }
 
-// Fatal
-func (l *Logger) Fatal(args ...interface{}) {
-	l.Logger.Fatal(args...)
+// Panic
+func (l *Logger) Panic(args ...interface{}) {
+	l.log().Panic(args...)
+}
+
+// Panicf
+func (l *Logger) Panicf(format string, args ...interface{}) {
+	l.log().Panicf(format, args...)
+}
+
+// Panicln
+func (l *Logger) Panicln(args ...interface{}) {
+	l.log().Panicln(args...)
+}
+
+type loggerCtxKey struct{}
+
+// NewContext returns ctx carrying request-scoped logger.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, logger)
+}
+
+// FromContext returns logger of ctx or fallback if ctx has no logger.
+func FromContext(ctx context.Context, fallback *Logger) *Logger {
+	if logger, ok := ctx.Value(loggerCtxKey{}).(*Logger); ok {
+		return logger
+	}
+	return fallback
+}
+
+// callerFrame returns frame skip levels above its caller.
+func callerFrame(skip int) (*runtime.Frame, bool) {
+	pc := make([]uintptr, 1)
+	if runtime.Callers(skip+1, pc) == 0 {
+		return nil, false
+	}
+	frame, _ := runtime.CallersFrames(pc).Next()
+	return &frame, true
 }
 
 // caller returns string presentation of log caller which is formatted as.
@@ -0,0 +1,175 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is suffix of rotated files, sorts in time order.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotateOptions configures RotatingFile. Zero value disables corresponding rule.
+type RotateOptions struct {
+	MaxSize    int64         // rotate when file would exceed MaxSize bytes
+	Interval   time.Duration // rotate at every Interval boundary (24h - at 00:00 UTC)
+	MaxBackups int           // keep at most MaxBackups rotated files
+	MaxAge     time.Duration // remove rotated files older than MaxAge
+}
+
+// RotatingFile is log file appended by Write, which is renamed to name-<time>.ext
+// and reopened when it grows over MaxSize or Interval passes.
+type RotatingFile struct {
+	path string
+	opts RotateOptions
+
+	mu           sync.Mutex
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+}
+
+// NewRotatingFile opens or creates file at path for appending.
+func NewRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	f := &RotatingFile{path: path, opts: opts}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	f.prune()
+
+	return f, nil
+}
+
+// Write writes p to file, rotating it before if needed.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// on rotation error line is still written to current file
+	if f.needRotation(len(p)) {
+		_ = f.rotate()
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes current file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
+
+func (f *RotatingFile) needRotation(writeLen int) bool {
+	if f.opts.MaxSize > 0 && f.size > 0 && f.size+int64(writeLen) > f.opts.MaxSize {
+		return true
+	}
+	return f.opts.Interval > 0 && !time.Now().Before(f.nextRotation)
+}
+
+func (f *RotatingFile) open() error {
+	file, size, err := openAppend(f.path)
+	if err != nil {
+		return err
+	}
+
+	f.file = file
+	f.size = size
+	if f.opts.Interval > 0 {
+		f.nextRotation = time.Now().Truncate(f.opts.Interval).Add(f.opts.Interval)
+	}
+	return nil
+}
+
+// rotate renames current file to backup and opens new one. Current file is closed
+// only after new one is opened, so on error f keeps writing to open current file.
+func (f *RotatingFile) rotate() error {
+	ext := filepath.Ext(f.path)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.path, ext), time.Now().UTC().Format(backupTimeFormat), ext)
+	// several rotations within a millisecond must not overwrite each other
+	for i := 1; fileExists(backup); i++ {
+		backup = fmt.Sprintf("%s-%s.%d%s", strings.TrimSuffix(f.path, ext), time.Now().UTC().Format(backupTimeFormat), i, ext)
+	}
+	if err := os.Rename(f.path, backup); err != nil {
+		return err
+	}
+
+	old := f.file
+	if err := f.open(); err != nil {
+		// current file is moved back to keep writing to path
+		_ = os.Rename(backup, f.path)
+		return err
+	}
+	_ = old.Close()
+	go f.prune()
+
+	return nil
+}
+
+// openAppend opens or creates file at path for appending and returns its size.
+func openAppend(path string) (*os.File, int64, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, 0, err
+	}
+	return file, info.Size(), nil
+}
+
+// prune removes rotated files over MaxBackups and older than MaxAge.
+func (f *RotatingFile) prune() {
+	if f.opts.MaxBackups <= 0 && f.opts.MaxAge <= 0 {
+		return
+	}
+
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(f.path, ext) + "-"
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return
+	}
+	backups := make([]string, 0, len(matches))
+	for _, match := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(match, prefix), ext)
+		if len(suffix) < len(backupTimeFormat) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, suffix[:len(backupTimeFormat)]); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	// newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	for i, backup := range backups {
+		remove := f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups
+		if !remove && f.opts.MaxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && time.Since(info.ModTime()) > f.opts.MaxAge {
+				remove = true
+			}
+		}
+		if remove {
+			_ = os.Remove(backup)
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
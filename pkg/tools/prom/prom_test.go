@@ -0,0 +1,62 @@
+package prom
+
+import (
+	"bytes"
+	"testing"
+)
+
+func expose(c collector) string {
+	r := &Registry{}
+	r.register(c)
+	var b bytes.Buffer
+	r.Expose(&b)
+	return b.String()
+}
+
+func TestCounterVec(t *testing.T) {
+	c := NewCounterVec("test_requests_total", "Requests\\count\nby path.", "path", "code")
+	c.Inc(`/a"b\c`+"\n", "200")
+	c.Add(2.5, "/", "500")
+	c.Inc("/", "500")
+	// mismatched label values are dropped
+	c.Inc("/")
+	c.Add(1, "/", "200", "extra")
+
+	want := `# HELP test_requests_total Requests\\count\nby path.
+# TYPE test_requests_total counter
+test_requests_total{path="/a\"b\\c\n",code="200"} 1
+test_requests_total{path="/",code="500"} 3.5
+`
+	if got := expose(c); got != want {
+		t.Errorf("exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	h := NewHistogramVec("test_duration_seconds", "Duration.", []float64{0.1, 1}, "route")
+	h.Observe(0.05, "/x")
+	h.Observe(0.5, "/x")
+	h.Observe(1, "/x")
+	h.Observe(3, "/x")
+	h.Observe(1, "/x", "extra")
+
+	want := `# HELP test_duration_seconds Duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/x",le="0.1"} 1
+test_duration_seconds_bucket{route="/x",le="1"} 3
+test_duration_seconds_bucket{route="/x",le="+Inf"} 4
+test_duration_seconds_sum{route="/x"} 4.55
+test_duration_seconds_count{route="/x"} 4
+`
+	if got := expose(h); got != want {
+		t.Errorf("exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGaugeFunc(t *testing.T) {
+	g := NewGaugeFunc("test_entries", "Entries.", func() float64 { return 42 })
+	want := "# HELP test_entries Entries.\n# TYPE test_entries gauge\ntest_entries 42\n"
+	if got := expose(g); got != want {
+		t.Errorf("exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
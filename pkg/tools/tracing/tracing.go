@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/stdout"
+	"go.opentelemetry.io/otel/propagators"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// exporters accepted by Options.Exporter
+const (
+	ExporterNone   = ""
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// Options configures tracing.
+type Options struct {
+	Exporter    string  // otlp, stdout or empty to disable tracing
+	Endpoint    string  // OTLP collector gRPC address, default localhost:55680
+	Insecure    bool    // disable TLS to OTLP collector
+	ServiceName string  // service.name resource attribute
+	SampleRatio float64 // share of sampled root traces, 0 means all
+}
+
+// Init installs global tracer provider exporting spans as configured and W3C trace context
+// and baggage propagators. Returned function flushes and stops exporting.
+// With ExporterNone spans are not recorded, but trace context is still propagated.
+func Init(opts Options) (func(context.Context) error, error) {
+	global.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	var (
+		exporter export.SpanExporter
+		err      error
+	)
+	switch opts.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporterOpts := make([]otlp.ExporterOption, 0)
+		if opts.Endpoint != "" {
+			exporterOpts = append(exporterOpts, otlp.WithAddress(opts.Endpoint))
+		}
+		if opts.Insecure {
+			exporterOpts = append(exporterOpts, otlp.WithInsecure())
+		}
+		exporter, err = otlp.NewExporter(exporterOpts...)
+	case ExporterStdout:
+		exporter, err = stdout.NewExporter(stdout.WithWriter(os.Stdout), stdout.WithoutMetricExport())
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", opts.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sampler := sdktrace.AlwaysSample()
+	if opts.SampleRatio > 0 && opts.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(opts.SampleRatio)
+	}
+
+	processor := sdktrace.NewBatchSpanProcessor(exporter)
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.ParentBased(sampler)}),
+		sdktrace.WithResource(resource.New(semconv.ServiceNameKey.String(opts.ServiceName))),
+		sdktrace.WithSpanProcessor(processor),
+	)
+	global.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		// flushes queued spans
+		processor.Shutdown()
+		return exporter.Shutdown(ctx)
+	}, nil
+}